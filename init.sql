@@ -1,31 +1,102 @@
 CREATE TABLE IF NOT EXISTS tax_rates (
   id SERIAL PRIMARY KEY,
+  tax_year INT NOT NULL,
   minimum_salary INT NOT NULL,
   maximum_salary INT NULL,
   rate INT NOT NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-INSERT INTO tax_rates (minimum_salary, maximum_salary, rate) VALUES 
-(0, 150000, 0),
-(150001, 500000, 10), --35,000 | 35,000
-(500001, 1000000, 15), -- 75,000 | 110,000
-(1000001, 2000000, 20), -- 200,000 | 310,000
-(2000001, 0, 35);
-
-CREATE TYPE deducation_type AS ENUM ('Personal', 'Donation','K-Receipt');
+INSERT INTO tax_rates (tax_year, minimum_salary, maximum_salary, rate)
+SELECT y, r.minimum_salary, r.maximum_salary, r.rate
+FROM generate_series(2566, 2569) AS y,
+(VALUES
+  (0, 150000, 0),
+  (150001, 500000, 10), --35,000 | 35,000
+  (500001, 1000000, 15), -- 75,000 | 110,000
+  (1000001, 2000000, 20), -- 200,000 | 310,000
+  (2000001, 0, 35)
+) AS r(minimum_salary, maximum_salary, rate);
 
 CREATE TABLE IF NOT EXISTS tax_deductions (
   id SERIAL PRIMARY KEY,
-  type deducation_type NOT NULL,
-  minimum_amount INT NOT NULL,
-  maximum_amount INT NULL,
-  amount INT NOT NULL,
+  tax_year INT NOT NULL,
+  type VARCHAR(30) NOT NULL,
+  minimum_amount NUMERIC(14, 2) NOT NULL,
+  maximum_amount NUMERIC(14, 2) NULL,
+  amount NUMERIC(14, 2) NOT NULL,
+  -- cap_percent > 0 also caps the deduction at that % of the income left
+  -- after the other deductions, applied in apply_order
+  cap_percent NUMERIC(5, 2) NOT NULL DEFAULT 0,
+  multiplier NUMERIC(5, 2) NOT NULL DEFAULT 1,
+  apply_order INT NOT NULL DEFAULT 0,
+  -- rows with the same group_name share the amount of deduction_groups
+  group_name VARCHAR(30) NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (tax_year, type)
+);
+
+INSERT INTO tax_deductions (tax_year, type, minimum_amount, maximum_amount, amount, cap_percent, multiplier, apply_order, group_name)
+SELECT y, d.type, d.minimum_amount, d.maximum_amount, d.amount, d.cap_percent, d.multiplier, d.apply_order, d.group_name
+FROM generate_series(2566, 2569) AS y,
+(VALUES
+  ('Personal', 10000, 100000, 60000, 0, 1, 0, ''),
+  ('Education-Donation', 0, 0, 0, 10, 2, 1, ''), -- counts double, max 10% of income after other deductions
+  ('Donation', 0, 100000, 100000, 10, 1, 2, ''), -- max 10% of income after other deductions and education donation
+  ('K-Receipt', 0, 100000, 50000, 0, 1, 0, ''),
+  ('Spouse', 0, 60000, 60000, 0, 1, 0, ''),
+  ('Child', 0, 30000, 30000, 0, 1, 0, ''),
+  ('Parents', 0, 120000, 120000, 0, 1, 0, ''),
+  ('Life-Insurance', 0, 100000, 100000, 0, 1, 0, 'Insurance'),
+  ('Health-Insurance', 0, 25000, 25000, 0, 1, 0, 'Insurance'),
+  ('Provident-Fund', 0, 500000, 500000, 0, 1, 0, 'Retirement'),
+  ('RMF', 0, 500000, 500000, 0, 1, 0, 'Retirement'),
+  ('SSF', 0, 200000, 200000, 0, 1, 0, 'Retirement'),
+  ('Pension-Insurance', 0, 200000, 200000, 0, 1, 0, 'Retirement'),
+  ('Social-Security', 0, 9000, 9000, 0, 1, 0, ''),
+  ('Home-Loan-Interest', 0, 100000, 100000, 0, 1, 0, '')
+) AS d(type, minimum_amount, maximum_amount, amount, cap_percent, multiplier, apply_order, group_name);
+
+CREATE TABLE IF NOT EXISTS deduction_groups (
+  id SERIAL PRIMARY KEY,
+  tax_year INT NOT NULL,
+  group_name VARCHAR(30) NOT NULL,
+  amount NUMERIC(14, 2) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (tax_year, group_name)
+);
+
+INSERT INTO deduction_groups (tax_year, group_name, amount)
+SELECT y, g.group_name, g.amount
+FROM generate_series(2566, 2569) AS y,
+(VALUES
+  ('Retirement', 500000), -- provident fund + rmf + ssf + pension insurance
+  ('Insurance', 100000) -- life + health insurance
+) AS g(group_name, amount);
+
+CREATE TABLE IF NOT EXISTS income_expense_rules (
+  id SERIAL PRIMARY KEY,
+  tax_year INT NOT NULL,
+  income_type VARCHAR(10) NOT NULL,
+  expense_group VARCHAR(20) NOT NULL,
+  rate NUMERIC(5, 2) NOT NULL,
+  maximum_amount NUMERIC(14, 2) NULL,
   created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+  UNIQUE (tax_year, income_type)
 );
 
-INSERT INTO tax_deductions (type, minimum_amount, maximum_amount, amount) VALUES 
-('Personal', 10000, 100000, 60000),
-('Donation', 0, 100000, 100000),
-('K-Receipt', 0, 100000, 50000);
\ No newline at end of file
+-- income types in the same expense_group share the group's maximum_amount
+INSERT INTO income_expense_rules (tax_year, income_type, expense_group, rate, maximum_amount)
+SELECT y, e.income_type, e.expense_group, e.rate, e.maximum_amount
+FROM generate_series(2566, 2569) AS y,
+(VALUES
+  ('40(1)', '40(1)-40(2)', 50, 100000),
+  ('40(2)', '40(1)-40(2)', 50, 100000),
+  ('40(3)', '40(3)', 50, 100000),
+  ('40(4)', '40(4)', 0, NULL),
+  ('40(5)', '40(5)', 30, NULL),
+  ('40(6)', '40(6)', 30, NULL),
+  ('40(7)', '40(7)', 60, NULL),
+  ('40(8)', '40(8)', 60, NULL)
+) AS e(income_type, expense_group, rate, maximum_amount);
\ No newline at end of file
@@ -24,6 +24,14 @@ func main() {
 	handler := tax.New(db)
 	e := echo.New()
 	e.POST("/tax/calculations", handler.TaxHandler)
+	e.POST("/tax/calculations/half-year", handler.HalfYearTaxHandler)
+	e.POST("/tax/calculations/batch", handler.BatchTaxHandler)
+	e.POST("/tax/calculations/reverse", handler.ReverseTaxHandler)
+	e.POST("/tax/calculations/late", handler.LateTaxHandler)
+	e.POST("/tax/calculations/sandbox", handler.SandboxTaxHandler)
+	e.POST("/tax/calculations/household", handler.HouseholdHandler)
+	e.POST("/tax/deductions/optimize", handler.OptimizeDeductionHandler)
+	e.POST("/tax/withholdings/monthly", handler.WithholdingHandler)
 	e.POST("tax/calculations/upload-csv", handler.UploadCSVHandler)
 
 	a := e.Group("/admin")
@@ -2,8 +2,8 @@ package postgres
 
 import "github.com/lMikadal/assessment-tax/tax"
 
-func (p *Postgres) GetTax() ([]tax.DB, error) {
-	rows, err := p.Db.Query("SELECT * FROM tax_rates")
+func (p *Postgres) GetTax(tax_year int) ([]tax.DB, error) {
+	rows, err := p.Db.Query("SELECT id, tax_year, minimum_salary, maximum_salary, rate, created_at FROM tax_rates WHERE tax_year = $1 ORDER BY minimum_salary", tax_year)
 	if err != nil {
 		return nil, err
 	}
@@ -12,7 +12,7 @@ func (p *Postgres) GetTax() ([]tax.DB, error) {
 	var tax_rates []tax.DB
 	for rows.Next() {
 		var tax_rate tax.DB
-		err := rows.Scan(&tax_rate.ID, &tax_rate.Minimum_salary, &tax_rate.Maximum_salary, &tax_rate.Rate, &tax_rate.Created_at)
+		err := rows.Scan(&tax_rate.ID, &tax_rate.Tax_year, &tax_rate.Minimum_salary, &tax_rate.Maximum_salary, &tax_rate.Rate, &tax_rate.Created_at)
 		if err != nil {
 			return nil, err
 		}
@@ -22,8 +22,66 @@ func (p *Postgres) GetTax() ([]tax.DB, error) {
 	return tax_rates, nil
 }
 
-func (p *Postgres) GetTaxDeducationByType(deducation_type string) (tax.DbDeduction, error) {
-	rows, err := p.Db.Query("SELECT * FROM tax_deductions WHERE type = $1", deducation_type)
+func (p *Postgres) GetTaxDeducations(tax_year int) ([]tax.DbDeduction, error) {
+	rows, err := p.Db.Query("SELECT id, tax_year, type, minimum_amount, maximum_amount, amount, cap_percent, multiplier, apply_order, group_name, created_at, updated_at FROM tax_deductions WHERE tax_year = $1 ORDER BY id", tax_year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tax_deductions []tax.DbDeduction
+	for rows.Next() {
+		var tax_deduction tax.DbDeduction
+		err := rows.Scan(&tax_deduction.ID, &tax_deduction.Tax_year, &tax_deduction.Type, &tax_deduction.Minimum_amount, &tax_deduction.Maximum_amount, &tax_deduction.Amount, &tax_deduction.Cap_percent, &tax_deduction.Multiplier, &tax_deduction.Apply_order, &tax_deduction.Group_name, &tax_deduction.Created_at, &tax_deduction.Updated_at)
+		if err != nil {
+			return nil, err
+		}
+		tax_deductions = append(tax_deductions, tax_deduction)
+	}
+
+	return tax_deductions, nil
+}
+
+func (p *Postgres) GetDeducationTypes() ([]string, error) {
+	rows, err := p.Db.Query("SELECT DISTINCT type FROM tax_deductions ORDER BY type")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deducation_types []string
+	for rows.Next() {
+		var deducation_type string
+		if err := rows.Scan(&deducation_type); err != nil {
+			return nil, err
+		}
+		deducation_types = append(deducation_types, deducation_type)
+	}
+
+	return deducation_types, nil
+}
+
+func (p *Postgres) GetTaxYears() ([]int, error) {
+	rows, err := p.Db.Query("SELECT DISTINCT tax_year FROM tax_rates ORDER BY tax_year")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tax_years []int
+	for rows.Next() {
+		var tax_year int
+		if err := rows.Scan(&tax_year); err != nil {
+			return nil, err
+		}
+		tax_years = append(tax_years, tax_year)
+	}
+
+	return tax_years, nil
+}
+
+func (p *Postgres) GetTaxDeducationByType(tax_year int, deducation_type string) (tax.DbDeduction, error) {
+	rows, err := p.Db.Query("SELECT id, tax_year, type, minimum_amount, maximum_amount, amount, cap_percent, multiplier, apply_order, group_name, created_at, updated_at FROM tax_deductions WHERE tax_year = $1 AND type = $2", tax_year, deducation_type)
 	if err != nil {
 		return tax.DbDeduction{}, err
 	}
@@ -31,7 +89,7 @@ func (p *Postgres) GetTaxDeducationByType(deducation_type string) (tax.DbDeducti
 
 	var tax_deduction tax.DbDeduction
 	for rows.Next() {
-		err := rows.Scan(&tax_deduction.ID, &tax_deduction.Type, &tax_deduction.Minimum_amount, &tax_deduction.Maximum_amount, &tax_deduction.Amount, &tax_deduction.Created_at, &tax_deduction.Updated_at)
+		err := rows.Scan(&tax_deduction.ID, &tax_deduction.Tax_year, &tax_deduction.Type, &tax_deduction.Minimum_amount, &tax_deduction.Maximum_amount, &tax_deduction.Amount, &tax_deduction.Cap_percent, &tax_deduction.Multiplier, &tax_deduction.Apply_order, &tax_deduction.Group_name, &tax_deduction.Created_at, &tax_deduction.Updated_at)
 		if err != nil {
 			return tax.DbDeduction{}, err
 		}
@@ -40,11 +98,51 @@ func (p *Postgres) GetTaxDeducationByType(deducation_type string) (tax.DbDeducti
 	return tax_deduction, nil
 }
 
-func (p *Postgres) SetTaxDeducationByType(deducation_type string, amount float64) error {
-	_, err := p.Db.Exec("UPDATE tax_deductions SET amount = $1 WHERE type = $2", amount, deducation_type)
+func (p *Postgres) SetTaxDeducationByType(tax_year int, deducation_type string, amount tax.Money) error {
+	_, err := p.Db.Exec("UPDATE tax_deductions SET amount = $1, updated_at = CURRENT_TIMESTAMP WHERE tax_year = $2 AND type = $3", amount, tax_year, deducation_type)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (p *Postgres) GetDeductionGroups(tax_year int) ([]tax.DbDeductionGroup, error) {
+	rows, err := p.Db.Query("SELECT id, tax_year, group_name, amount, created_at FROM deduction_groups WHERE tax_year = $1", tax_year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deduction_groups []tax.DbDeductionGroup
+	for rows.Next() {
+		var deduction_group tax.DbDeductionGroup
+		err := rows.Scan(&deduction_group.ID, &deduction_group.Tax_year, &deduction_group.Group_name, &deduction_group.Amount, &deduction_group.Created_at)
+		if err != nil {
+			return nil, err
+		}
+		deduction_groups = append(deduction_groups, deduction_group)
+	}
+
+	return deduction_groups, nil
+}
+
+func (p *Postgres) GetExpenseRules(tax_year int) ([]tax.DbExpenseRule, error) {
+	rows, err := p.Db.Query("SELECT id, tax_year, income_type, expense_group, rate, maximum_amount, created_at FROM income_expense_rules WHERE tax_year = $1 ORDER BY id", tax_year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expense_rules []tax.DbExpenseRule
+	for rows.Next() {
+		var expense_rule tax.DbExpenseRule
+		err := rows.Scan(&expense_rule.ID, &expense_rule.Tax_year, &expense_rule.Income_type, &expense_rule.Expense_group, &expense_rule.Rate, &expense_rule.Maximum_amount, &expense_rule.Created_at)
+		if err != nil {
+			return nil, err
+		}
+		expense_rules = append(expense_rules, expense_rule)
+	}
+
+	return expense_rules, nil
+}
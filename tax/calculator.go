@@ -0,0 +1,380 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// ErrTaxYearNotFound is returned when no tax rates are configured for the
+// requested tax year.
+var ErrTaxYearNotFound = errors.New("tax year not found")
+
+// Calculator computes progressive tax from a snapshot of tax rates and
+// deductions. It never touches the database or the HTTP layer, so one
+// snapshot can be loaded once and reused for every row of a batch.
+type Calculator struct {
+	Rates           []DB
+	Deductions      map[string]DbDeduction
+	DeductionGroups map[string]DbDeductionGroup
+	ExpenseRules    []DbExpenseRule
+
+	explain  bool
+	halfYear bool
+	lang     language.Tag
+}
+
+// NewCalculator builds a Calculator from already loaded rates and deductions.
+// Deductions are keyed by lower-case type, e.g. "personal" or "k-receipt".
+func NewCalculator(rates []DB, deductions map[string]DbDeduction) Calculator {
+	return Calculator{
+		Rates:      rates,
+		Deductions: deductions,
+	}
+}
+
+func (t Tax) loadCalculator(tax_year int) (Calculator, error) {
+	tax_rate, err := t.loadTaxRate(tax_year)
+	if err != nil {
+		return Calculator{}, err
+	}
+
+	tax_deductions, err := t.info.GetTaxDeducations(tax_year)
+	if err != nil {
+		return Calculator{}, fmt.Errorf("failed to get deduction: %v", err)
+	}
+	deductions := make(map[string]DbDeduction)
+	for _, v := range tax_deductions {
+		deductions[strings.ToLower(v.Type)] = v
+	}
+
+	deduction_groups, err := t.info.GetDeductionGroups(tax_year)
+	if err != nil {
+		return Calculator{}, fmt.Errorf("failed to get deduction group: %v", err)
+	}
+	groups := make(map[string]DbDeductionGroup)
+	for _, v := range deduction_groups {
+		groups[v.Group_name] = v
+	}
+
+	expense_rules, err := t.info.GetExpenseRules(tax_year)
+	if err != nil {
+		return Calculator{}, fmt.Errorf("failed to get expense rule: %v", err)
+	}
+
+	calculator := NewCalculator(tax_rate, deductions)
+	calculator.DeductionGroups = groups
+	calculator.ExpenseRules = expense_rules
+
+	return calculator, nil
+}
+
+// cachedCalculator returns the calculator of tax_year from calculators,
+// loading and adding it on first use so a batch reads each tax year once.
+func (t Tax) cachedCalculator(calculators map[int]Calculator, tax_year int, lang language.Tag) (Calculator, error) {
+	if calculator, ok := calculators[tax_year]; ok {
+		return calculator, nil
+	}
+
+	calculator, err := t.loadCalculator(tax_year)
+	if err != nil {
+		return Calculator{}, err
+	}
+	calculator = calculator.WithLanguage(lang)
+	calculators[tax_year] = calculator
+
+	return calculator, nil
+}
+
+// AllowanceTypes returns the allowance types a request may send, which are all
+// configured deductions except the personal deduction, in sorted order.
+func (c Calculator) AllowanceTypes() []string {
+	var allowance_types []string
+	for k := range c.Deductions {
+		if k != "personal" {
+			allowance_types = append(allowance_types, k)
+		}
+	}
+	slices.Sort(allowance_types)
+
+	return allowance_types
+}
+
+func (t Tax) loadTaxRate(tax_year int) ([]DB, error) {
+	tax_rate, err := t.info.GetTax(tax_year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax rate: %v", err)
+	}
+	if len(tax_rate) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrTaxYearNotFound, tax_year)
+	}
+
+	return tax_rate, nil
+}
+
+// Calculate applies the expense deduction of each income, the personal
+// deduction and the capped allowances to the income, runs it through the tax
+// brackets and subtracts wht and the PND94 tax paid for the first half of the
+// year. A request without incomes is taken as already net of expenses.
+func (c Calculator) Calculate(req ReqTax) ResTaxLevel {
+	return c.calculate(req, req.grossIncome(), c.expenses(req.Incomes))
+}
+
+// WithLanguage returns a copy of c that formats tax level labels in lang.
+func (c Calculator) WithLanguage(lang language.Tag) Calculator {
+	c.lang = lang
+
+	return c
+}
+
+// Explain is Calculate with the Trace of how the result was derived.
+func (c Calculator) Explain(req ReqTax) ResTaxLevel {
+	c.explain = true
+
+	return c.Calculate(req)
+}
+
+// calculate is Calculate with the gross income and expenses worked out by the
+// caller, for returns that combine the incomes of more than one person.
+func (c Calculator) calculate(req ReqTax, gross Money, expenses []AppliedExpense) ResTaxLevel {
+	var res ResTaxLevel
+	income := gross
+	trace := []TraceStep{{Step: "grossIncome", Amount: gross, Balance: income}}
+	res.Expenses = expenses
+	for _, v := range res.Expenses {
+		income -= v.Expense
+		trace = append(trace, TraceStep{Step: "expense", Name: v.ExpenseGroup, Income: v.Income, Amount: v.Expense, Balance: income})
+	}
+
+	personal := c.Deductions["personal"].Amount
+	res.Deductions = append(res.Deductions, AppliedDeduction{
+		Type:      "personal",
+		Requested: personal,
+		Applied:   personal,
+	})
+	income -= personal
+	trace = append(trace, TraceStep{
+		Step:        "deduction",
+		Name:        "personal",
+		Requested:   personal,
+		Limit:       personal,
+		LimitSource: "tax_deductions.amount",
+		Amount:      personal,
+		Balance:     income,
+	})
+	var allowances []AppliedDeduction
+	var allowance_trace []TraceStep
+	allowances, res.DeductionGroups, income, allowance_trace = c.applyAllowances(req.Allowances, income)
+	res.Deductions = append(res.Deductions, allowances...)
+	trace = append(trace, allowance_trace...)
+	if income > 0 {
+		res.TaxableIncome = income
+	}
+	trace = append(trace, TraceStep{Step: "taxableIncome", Amount: res.TaxableIncome, Balance: res.TaxableIncome})
+
+	var marginal int
+	res.Tax, res.TaxLevel, marginal = c.progressive(income)
+	var bracket_tax Money
+	for i, v := range c.bracketIncome(income) {
+		if v <= 0 {
+			continue
+		}
+		bracket_tax += res.TaxLevel[i].Tax
+		trace = append(trace, TraceStep{
+			Step:    "bracket",
+			Name:    res.TaxLevel[i].Level,
+			Rate:    c.Rates[i].Rate,
+			Income:  v,
+			Amount:  res.TaxLevel[i].Tax,
+			Balance: bracket_tax,
+		})
+	}
+	res.Tax, res.TaxMethod = c.minimumTax(req.Incomes, res.Tax)
+	if res.TaxMethod != nil {
+		trace = append(trace, TraceStep{
+			Step:    "minimumTax",
+			Name:    res.TaxMethod.Method,
+			Rate:    minimum_tax_rate,
+			Amount:  res.TaxMethod.MinimumTax,
+			Balance: res.Tax,
+		})
+	}
+	if marginal >= 0 {
+		res.MarginalLevel = levelName(c.Rates[marginal], newPrinter(c.lang))
+		res.MarginalRate = c.Rates[marginal].Rate
+	}
+	res.EffectiveRate = effectiveRate(res.Tax, gross)
+
+	res.Tax -= req.Wht
+	trace = append(trace, TraceStep{Step: "wht", Amount: req.Wht, Balance: res.Tax})
+	if req.Pnd94 != 0 {
+		res.Tax -= req.Pnd94
+		res.TaxCredit = &TaxCredit{Wht: req.Wht, Pnd94: req.Pnd94, Total: req.Wht + req.Pnd94}
+		trace = append(trace, TraceStep{Step: "pnd94", Amount: req.Pnd94, Balance: res.Tax})
+	}
+	if res.Tax < 0 {
+		res.TaxRefund = res.Tax * -1
+		res.Tax = 0
+		trace = append(trace, TraceStep{Step: "taxRefund", Amount: res.TaxRefund, Balance: res.TaxRefund})
+	} else {
+		trace = append(trace, TraceStep{Step: "tax", Amount: res.Tax, Balance: res.Tax})
+	}
+
+	if c.explain {
+		res.Trace = trace
+	}
+
+	return res
+}
+
+// applyAllowances deducts allowances from income and returns what was applied
+// for each of them, the usage of every deduction group they touched, the
+// income left and a trace step per allowance naming the limit that applied.
+//
+// Allowances with a flat cap are applied first in request order. Percentage
+// capped allowances such as donations are then capped on the income left
+// after every other deduction, in apply_order, so each one also sees the ones
+// applied before it. An allowance in a deduction group is further trimmed to
+// what is left of the group's combined cap, first come first served.
+func (c Calculator) applyAllowances(req_allowances []Allowance, income Money) ([]AppliedDeduction, []AppliedGroup, Money, []TraceStep) {
+	var flat, percent []Allowance
+	for _, v := range req_allowances {
+		if c.Deductions[strings.ToLower(v.AllowanceType)].Cap_percent != 0 {
+			percent = append(percent, v)
+		} else {
+			flat = append(flat, v)
+		}
+	}
+	slices.SortStableFunc(percent, func(a, b Allowance) int {
+		return c.Deductions[strings.ToLower(a.AllowanceType)].Apply_order - c.Deductions[strings.ToLower(b.AllowanceType)].Apply_order
+	})
+
+	var applied []AppliedDeduction
+	var groups []AppliedGroup
+	var trace []TraceStep
+	group_index := make(map[string]int)
+	for _, v := range append(flat, percent...) {
+		allowance_type := strings.ToLower(v.AllowanceType)
+		amount, limit, limit_source := c.capAllowance(v, income)
+
+		group_name := c.Deductions[allowance_type].Group_name
+		if group, ok := c.DeductionGroups[group_name]; ok && group_name != "" {
+			i, ok := group_index[group_name]
+			if !ok {
+				groups = append(groups, AppliedGroup{Group: group_name, Limit: group.Amount})
+				i = len(groups) - 1
+				group_index[group_name] = i
+			}
+			if remaining := groups[i].Limit - groups[i].Applied; remaining < limit {
+				limit, limit_source = remaining, "deduction_groups."+group_name
+			}
+			amount = min(amount, limit)
+			groups[i].Applied += amount
+		}
+
+		applied = append(applied, AppliedDeduction{
+			Type:      allowance_type,
+			Group:     group_name,
+			Requested: v.Amount,
+			Applied:   amount,
+		})
+		income -= amount
+		trace = append(trace, TraceStep{
+			Step:        "deduction",
+			Name:        allowance_type,
+			Requested:   v.Amount,
+			Limit:       limit,
+			LimitSource: limit_source,
+			Amount:      amount,
+			Balance:     income,
+		})
+	}
+
+	return applied, groups, income, trace
+}
+
+// capAllowance returns the allowance after its multiplier and cap, together
+// with the cap and the tax_deductions column it came from.
+func (c Calculator) capAllowance(allowance Allowance, income Money) (Money, Money, string) {
+	deduction := c.Deductions[strings.ToLower(allowance.AllowanceType)]
+	amount := allowance.Amount
+	if deduction.Multiplier != 0 {
+		amount = amount.Percent(deduction.Multiplier * 100)
+	}
+
+	limit, limit_source := deduction.Amount, "tax_deductions.amount"
+	if deduction.Cap_percent != 0 {
+		limit_percent := max(income, 0).Percent(deduction.Cap_percent)
+		if deduction.Amount == 0 || limit_percent < limit {
+			limit, limit_source = limit_percent, "tax_deductions.cap_percent"
+		}
+	}
+
+	return min(amount, limit), limit, limit_source
+}
+
+// progressive returns the tax, the tax of each bracket and the index of the
+// marginal bracket, which is the last bracket the income reaches.
+func (c Calculator) progressive(income Money) (Money, []TaxLevel, int) {
+	var tax Money
+	var level []TaxLevel
+	var cal Money
+	printer := newPrinter(c.lang)
+	marginal := -1
+	if len(c.Rates) > 0 {
+		marginal = 0
+	}
+	for i, v := range c.bracketIncome(income) {
+		cal = 0
+		if v > 0 {
+			cal = calculateTax(v, c.Rates[i])
+			tax += cal
+			marginal = i
+		}
+
+		addTaxLevel(&level, c.Rates[i], cal, printer)
+	}
+
+	return tax, level, marginal
+}
+
+// bracketIncome splits income into the portion that falls in each bracket.
+// The first bracket starts at 0 and every later one a baht after the end of
+// the one before, so all but the first are a baht wider than max - min.
+func (c Calculator) bracketIncome(income Money) []Money {
+	portions := make([]Money, len(c.Rates))
+	var rang_now Money
+	for i, v := range c.Rates {
+		if income <= 0 {
+			break
+		}
+
+		rang_now = v.Maximum_salary - v.Minimum_salary
+		if i > 0 {
+			rang_now += Baht(1)
+		}
+
+		if rang_now > income || v.Maximum_salary == 0 {
+			portions[i] = income
+		} else {
+			portions[i] = rang_now
+		}
+		income -= rang_now
+	}
+
+	return portions
+}
+
+// effectiveRate returns tax as a percentage of income, rounded to two
+// decimal places.
+func effectiveRate(tax Money, income Money) float64 {
+	if income <= 0 {
+		return 0
+	}
+
+	return math.Round(float64(tax)*10000/float64(income)) / 100
+}
@@ -0,0 +1,78 @@
+//go:build unit
+
+package tax
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCalculator(t *testing.T) {
+	rates, _ := MockTax{}.GetTax(2567)
+	calculator := NewCalculator(rates, map[string]DbDeduction{
+		"personal":  {Type: "Personal", Amount: Baht(60000)},
+		"donation":  {Type: "Donation", Amount: Baht(100000)},
+		"k-receipt": {Type: "K-Receipt", Amount: Baht(50000)},
+	})
+
+	t.Run("Test allowances are capped", func(t *testing.T) {
+		got := calculator.Calculate(ReqTax{
+			TotalIncome: Baht(500000),
+			Allowances: []Allowance{
+				{AllowanceType: "k-receipt", Amount: Baht(200000)},
+				{AllowanceType: "Donation", Amount: Baht(100000)},
+			},
+		})
+
+		want := ResTaxLevel{
+			Tax: Baht(14000),
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(290000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "k-receipt", Requested: Baht(200000), Applied: Baht(50000)},
+					{Type: "donation", Requested: Baht(100000), Applied: Baht(100000)},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 2.8,
+			},
+			TaxLevel: []TaxLevel{
+				{Level: "0-150,000", Tax: 0},
+				{Level: "150,001-500,000", Tax: Baht(14000)},
+				{Level: "500,001-1,000,000", Tax: 0},
+				{Level: "1,000,001-2,000,000", Tax: 0},
+				{Level: "2,000,001 ขึ้นไป", Tax: 0},
+			},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test wht great than tax output taxRefund", func(t *testing.T) {
+		got := calculator.Calculate(ReqTax{
+			TotalIncome: Baht(500000),
+			Wht:         Baht(30000),
+		})
+
+		if got.Tax != 0 || got.TaxRefund != Baht(1000) {
+			t.Errorf("got: %v, want tax: 0, taxRefund: 1000", got)
+		}
+	})
+
+	t.Run("Test income less than personal deduction", func(t *testing.T) {
+		got := calculator.Calculate(ReqTax{
+			TotalIncome: Baht(50000),
+		})
+
+		if got.Tax != 0 || got.TaxRefund != 0 || got.TaxableIncome != 0 {
+			t.Errorf("got: %v, want tax: 0, taxRefund: 0, taxableIncome: 0", got)
+		}
+
+		if got.MarginalLevel != "0-150,000" || got.EffectiveRate != 0 {
+			t.Errorf("got: %v, want marginalLevel: 0-150,000, effectiveRate: 0", got)
+		}
+	})
+}
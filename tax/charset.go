@@ -0,0 +1,91 @@
+package tax
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// csv_charsets are the charsets a csv can be sent in besides UTF-8. TIS-620
+// and ISO-8859-11 are read as Windows-874, which only adds characters to them.
+var csv_charsets = map[string]encoding.Encoding{
+	"windows-874": charmap.Windows874,
+	"cp874":       charmap.Windows874,
+	"tis-620":     charmap.Windows874,
+	"iso-8859-11": charmap.Windows874,
+}
+
+var utf8_bom = []byte("\xef\xbb\xbf")
+
+// csv_sniff_size is how much of a csv is looked at to guess its charset and
+// delimiter.
+const csv_sniff_size = 4096
+
+// decodeCsv returns body as UTF-8 without a byte order mark. Without a charset
+// the body is taken as UTF-8 when it starts with a BOM or is valid UTF-8, and
+// as Windows-874 otherwise, since that is what Thai accounting tools export.
+// It returns false when the charset is not known.
+func decodeCsv(body io.Reader, charset string) (*bufio.Reader, bool) {
+	buffered := bufio.NewReaderSize(body, csv_sniff_size)
+	charset = strings.ToLower(charset)
+	if charset == "" {
+		sniff, err := buffered.Peek(csv_sniff_size)
+		if !bytes.HasPrefix(sniff, utf8_bom) && !validUTF8(sniff, err == nil) {
+			charset = "windows-874"
+		}
+	}
+
+	var decoded io.Reader = buffered
+	if charset != "" && charset != "utf-8" && charset != "utf8" {
+		enc, ok := csv_charsets[charset]
+		if !ok {
+			return nil, false
+		}
+		decoded = enc.NewDecoder().Reader(buffered)
+	}
+
+	reader := bufio.NewReaderSize(decoded, csv_sniff_size)
+	if bom, err := reader.Peek(len(utf8_bom)); err == nil && bytes.Equal(bom, utf8_bom) {
+		reader.Discard(len(utf8_bom))
+	}
+
+	return reader, true
+}
+
+// validUTF8 tells whether p is valid UTF-8. When p is only the start of the
+// body, a rune cut off at its end does not count against it.
+func validUTF8(p []byte, partial bool) bool {
+	if partial {
+		for n := len(p); n > 0 && n > len(p)-utf8.UTFMax; n-- {
+			if utf8.RuneStart(p[n-1]) {
+				if !utf8.FullRune(p[n-1:]) {
+					p = p[:n-1]
+				}
+				break
+			}
+		}
+	}
+
+	return utf8.Valid(p)
+}
+
+// csvDelimiter guesses the delimiter of a csv from its header line: a
+// semicolon or a tab when the line has more of them than commas.
+func csvDelimiter(reader *bufio.Reader) rune {
+	sniff, _ := reader.Peek(csv_sniff_size)
+	line, _, _ := bytes.Cut(sniff, []byte("\n"))
+
+	delimiter, count := ',', bytes.Count(line, []byte(","))
+	for _, v := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(v))); n > count {
+			delimiter, count = v, n
+		}
+	}
+
+	return delimiter
+}
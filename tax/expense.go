@@ -0,0 +1,96 @@
+package tax
+
+// half_year_income_types are the income types that are filed for the first
+// half of the year on PND94.
+var half_year_income_types = []string{"40(5)", "40(6)", "40(7)", "40(8)"}
+
+// income_types are the assessable income types of section 40 of the Revenue
+// Code that can be sent in ReqTax.Incomes.
+var income_types = []string{"40(1)", "40(2)", "40(3)", "40(4)", "40(5)", "40(6)", "40(7)", "40(8)"}
+
+// minimum_tax_exemption is section 48(2): a taxpayer whose 0.5% would not be
+// more than this many baht does not pay by that method.
+const (
+	minimum_tax_rate      = 0.5
+	minimum_tax_threshold = 120000
+	minimum_tax_exemption = 5000
+)
+
+// grossIncome is the sum of req.Incomes when they are given, otherwise
+// req.TotalIncome.
+func (req ReqTax) grossIncome() Money {
+	if len(req.Incomes) == 0 {
+		return req.TotalIncome
+	}
+
+	var total Money
+	for _, v := range req.Incomes {
+		total += v.Amount
+	}
+
+	return total
+}
+
+// expenses applies the standard expense deduction of every income. Each rule
+// deducts rate percent of its income type, and all income types that share an
+// expense group share the group's maximum, e.g. 40(1) and 40(2) together are
+// capped at 100,000. A maximum of 0 means no cap. Income types without a rule
+// have no expense deduction.
+func (c Calculator) expenses(incomes []Income) []AppliedExpense {
+	var applied []AppliedExpense
+	group_index := make(map[string]int)
+	for _, rule := range c.ExpenseRules {
+		for _, v := range incomes {
+			if v.IncomeType != rule.Income_type {
+				continue
+			}
+
+			i, ok := group_index[rule.Expense_group]
+			if !ok {
+				applied = append(applied, AppliedExpense{ExpenseGroup: rule.Expense_group})
+				i = len(applied) - 1
+				group_index[rule.Expense_group] = i
+			}
+			applied[i].Income += v.Amount
+			applied[i].Expense += v.Amount.Percent(rule.Rate)
+			if rule.Maximum_amount != 0 && applied[i].Expense > rule.Maximum_amount {
+				applied[i].Expense = rule.Maximum_amount
+			}
+		}
+	}
+
+	return applied
+}
+
+// minimumTax compares the progressive tax with 0.5% of the income other than
+// 40(1). The comparison only applies when that income is above 120,000, or
+// 60,000 for the half-year return, in which case the higher of the two is
+// payable and reported in TaxMethod. A 0.5% of no more than 5,000 is exempt,
+// so the progressive tax is payable even when it is lower.
+func (c Calculator) minimumTax(incomes []Income, progressive Money) (Money, *TaxMethod) {
+	var base Money
+	for _, v := range incomes {
+		if v.IncomeType != "40(1)" {
+			base += v.Amount
+		}
+	}
+	threshold := Baht(minimum_tax_threshold)
+	if c.halfYear {
+		threshold /= 2
+	}
+	if base <= threshold {
+		return progressive, nil
+	}
+
+	method := &TaxMethod{
+		Method:         "progressive",
+		ProgressiveTax: progressive,
+		MinimumTax:     base.Percent(minimum_tax_rate),
+	}
+	if method.MinimumTax > Baht(minimum_tax_exemption) && method.MinimumTax > progressive {
+		method.Method = "minimum"
+		return method.MinimumTax, method
+	}
+
+	return progressive, method
+}
@@ -0,0 +1,27 @@
+package tax
+
+// HalfYear calculates the PND94 return for the 40(5)-40(8) income of the
+// first half of the year. The brackets are those of the full year while every
+// flat allowance cap, including the personal deduction and the deduction
+// group caps, is halved. Percentage caps stay as they are since they already
+// scale with the income.
+//
+// The tax paid on this return is credited in the annual return through
+// ReqTax.Pnd94.
+func (c Calculator) HalfYear(req ReqTax) ResTaxLevel {
+	half := c
+	half.halfYear = true
+
+	half.Deductions = make(map[string]DbDeduction, len(c.Deductions))
+	for k, v := range c.Deductions {
+		v.Amount /= 2
+		half.Deductions[k] = v
+	}
+	half.DeductionGroups = make(map[string]DbDeductionGroup, len(c.DeductionGroups))
+	for k, v := range c.DeductionGroups {
+		v.Amount /= 2
+		half.DeductionGroups[k] = v
+	}
+
+	return half.Calculate(req)
+}
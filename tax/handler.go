@@ -1,156 +1,345 @@
 package tax
 
 import (
-	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
+// max_batch_size is the most requests BatchTaxHandler takes at once.
+const max_batch_size = 1000
+
+// max_csv_file_size is the largest file UploadCSVHandler takes from a
+// multipart form and max_csv_upload_size the largest whole form. Raw csv
+// bodies have no limit, so they can still be streamed.
+const (
+	max_csv_file_size   = 10 << 20
+	max_csv_upload_size = 50 << 20
+)
+
+const mime_ndjson = "application/x-ndjson"
+
+const mime_csv = "text/csv"
+
+// calculationReq is a request body of a handler that calculates the tax of one
+// tax year. The methods are on the pointer so prepare can default the year
+// after binding.
+type calculationReq interface {
+	taxYear() *int
+	allowanceLists() [][]Allowance
+}
+
+// calculatorOverride is the optional part of a calculationReq: a request that
+// changes the loaded calculator, like the brackets and deductions of
+// ReqSandbox, does so in apply before its allowances are checked.
+type calculatorOverride interface {
+	apply(c Calculator) Calculator
+}
+
+func (req *ReqTax) taxYear() *int { return &req.TaxYear }
+
+func (req *ReqTax) allowanceLists() [][]Allowance { return [][]Allowance{req.Allowances} }
+
+func (req *ReqReverseTax) taxYear() *int { return &req.TaxYear }
+
+func (req *ReqReverseTax) allowanceLists() [][]Allowance { return [][]Allowance{req.Allowances} }
+
+func (req *ReqWithholding) taxYear() *int { return &req.TaxYear }
+
+func (req *ReqWithholding) allowanceLists() [][]Allowance { return [][]Allowance{req.Allowances} }
+
+func (req *ReqHousehold) taxYear() *int { return &req.TaxYear }
+
+func (req *ReqHousehold) allowanceLists() [][]Allowance {
+	return [][]Allowance{req.Taxpayer.Allowances, req.Spouse.Allowances}
+}
+
+func (req *ReqSandbox) apply(c Calculator) Calculator { return c.Sandbox(req.Brackets, req.Deductions) }
+
+// prepare does the setup every calculation handler shares: it binds the body
+// into req, checks it with validate, defaults its tax year, loads the
+// calculator of that year in the request language, lets a calculatorOverride
+// change it and checks the allowances against the calculator. A non-empty Err is the response to send with the status.
+func (t Tax) prepare(c echo.Context, req calculationReq, validate func(*message.Printer) (bool, Err)) (Calculator, int, Err) {
+	lang := requestLanguage(c)
+	printer := newPrinter(lang)
+	if err := c.Bind(req); err != nil {
+		return Calculator{}, http.StatusBadRequest, Err{Message: printer.Sprintf("invalid request")}
+	}
+
+	if ok, msg := validate(printer); !ok {
+		return Calculator{}, http.StatusBadRequest, msg
+	}
+	tax_year := req.taxYear()
+	if *tax_year == 0 {
+		*tax_year = t.defaultTaxYear()
+	}
+
+	calculator, err := t.loadCalculator(*tax_year)
+	if err != nil {
+		return Calculator{}, errStatus(err), Err{Message: errMessage(err, *tax_year, printer)}
+	}
+	if override, ok := req.(calculatorOverride); ok {
+		calculator = override.apply(calculator)
+	}
+	calculator = calculator.WithLanguage(lang)
+	for _, allowances := range req.allowanceLists() {
+		if ok, msg := t.validateAllowances(allowances, calculator.AllowanceTypes(), printer); !ok {
+			return Calculator{}, http.StatusBadRequest, msg
+		}
+	}
+
+	return calculator, http.StatusOK, Err{}
+}
+
 func (t Tax) TaxHandler(c echo.Context) error {
 	var req ReqTax
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, Err{Message: "invalid request"})
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		return t.validateReq(req, printer)
+	})
+	if msg.Message != "" {
+		return c.JSON(status, msg)
 	}
 
-	if ok, err := t.validateReq(req); !ok {
-		return c.JSON(http.StatusBadRequest, err)
+	if c.QueryParam("explain") == "true" {
+		return c.JSON(http.StatusOK, calculator.Explain(req))
 	}
 
-	personal, err := t.info.GetTaxDeducationByType("Personal")
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to get personal deduction: %v", err)})
+	return c.JSON(http.StatusOK, calculator.Calculate(req))
+}
+
+func (t Tax) HalfYearTaxHandler(c echo.Context) error {
+	var req ReqTax
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		return t.validateHalfYearReq(req, printer)
+	})
+	if msg.Message != "" {
+		return c.JSON(status, msg)
 	}
 
-	req.TotalIncome -= personal.Amount
-	if len(req.Allowances) > 0 {
-		for _, v := range req.Allowances {
-			deduction, err := t.info.GetTaxDeducationByType(cases.Title(language.English, cases.Compact).String(strings.ToLower(v.AllowanceType)))
-			if err != nil {
-				return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to get deduction: %v", err)})
-			}
-			if v.Amount > deduction.Amount {
-				req.TotalIncome -= deduction.Amount
-			} else {
-				req.TotalIncome -= v.Amount
-			}
+	return c.JSON(http.StatusOK, calculator.HalfYear(req))
+}
+
+func (t Tax) LateTaxHandler(c echo.Context) error {
+	var req ReqLateTax
+	var filing, payment time.Time
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		if ok, msg := t.validateReq(req.ReqTax, printer); !ok {
+			return false, msg
 		}
+		var msg Err
+		filing, payment, msg = t.lateDates(req, printer)
+		return msg.Message == "", msg
+	})
+	if msg.Message != "" {
+		return c.JSON(status, msg)
 	}
 
-	tax_rate, err := t.info.GetTax()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to get tax rate: %v", err)})
-	}
-	var res ResTaxLevel
-	var rang_now float64
-	var cal float64
-	for _, v := range tax_rate {
-		rang_now = v.Maximum_salary - v.Minimum_salary
-		if v.Rate != 0 {
-			rang_now += 1
-		}
+	return c.JSON(http.StatusOK, calculator.Late(req.ReqTax, filing, payment))
+}
 
-		if req.TotalIncome <= 0 {
-			cal = 0
-		} else {
-			if rang_now > req.TotalIncome || v.Maximum_salary == 0 {
-				cal = t.calculateTax(req.TotalIncome, v)
-			} else {
-				cal = t.calculateTax(rang_now, v)
-			}
-			res.Tax += cal
-			req.TotalIncome -= rang_now
-		}
+func (t Tax) SandboxTaxHandler(c echo.Context) error {
+	var req ReqSandbox
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		return t.validateSandboxReq(req, printer)
+	})
+	if msg.Message != "" {
+		return c.JSON(status, msg)
+	}
+
+	if c.QueryParam("explain") == "true" {
+		return c.JSON(http.StatusOK, calculator.Explain(req.ReqTax))
+	}
 
-		t.addTaxLevel(&res.TaxLevel, v, cal)
+	return c.JSON(http.StatusOK, calculator.Calculate(req.ReqTax))
+}
+
+func (t Tax) ReverseTaxHandler(c echo.Context) error {
+	var req ReqReverseTax
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		return t.validateReverseReq(req, printer)
+	})
+	if msg.Message != "" {
+		return c.JSON(status, msg)
 	}
 
-	res.Tax -= req.Wht
-	if res.Tax < 0 {
-		res.TaxRefund = res.Tax * -1
-		res.Tax = 0
+	res, err := calculator.Reverse(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: errMessage(err, req.TaxYear, newPrinter(calculator.lang))})
 	}
 
 	return c.JSON(http.StatusOK, res)
 }
 
-func (t Tax) UploadCSVHandler(c echo.Context) error {
-	reader := csv.NewReader(c.Request().Body)
-	read, err := reader.ReadAll()
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, Err{Message: "failed to read csv"})
+func (t Tax) OptimizeDeductionHandler(c echo.Context) error {
+	var req ReqTax
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		return t.validateReq(req, printer)
+	})
+	if msg.Message != "" {
+		return c.JSON(status, msg)
+	}
+
+	return c.JSON(http.StatusOK, calculator.Optimize(req))
+}
+
+func (t Tax) HouseholdHandler(c echo.Context) error {
+	var req ReqHousehold
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		return t.validateHouseholdReq(req, printer)
+	})
+	if msg.Message != "" {
+		return c.JSON(status, msg)
 	}
 
-	position, deducate, msg := t.validateCsv(read[0])
+	return c.JSON(http.StatusOK, calculator.Household(req))
+}
+
+func (t Tax) WithholdingHandler(c echo.Context) error {
+	var req ReqWithholding
+	calculator, status, msg := t.prepare(c, &req, func(printer *message.Printer) (bool, Err) {
+		return t.validateWithholdingReq(req, printer)
+	})
 	if msg.Message != "" {
-		return c.JSON(http.StatusBadRequest, msg)
-	}
-	if len(read) <= 1 {
-		return c.JSON(http.StatusBadRequest, Err{Message: "invalid csv have not value"})
-	}
-	income := 0.0
-	wht := 0.0
-	res_all_csv := ResAllCsv{}
-	for _, v := range read[1:] {
-		res_csv := ResCsvTax{}
-		if _, ok := position["totalIncome"]; ok {
-			income, err = strconv.ParseFloat(v[position["totalIncome"]], 64)
-			if err != nil {
-				return c.JSON(http.StatusBadRequest, Err{Message: "invalid field totalIncome"})
-			}
+		return c.JSON(status, msg)
+	}
+
+	return c.JSON(http.StatusOK, calculator.Withhold(req))
+}
+
+func (t Tax) BatchTaxHandler(c echo.Context) error {
+	lang := requestLanguage(c)
+	printer := newPrinter(lang)
+	var reqs []ReqTax
+	if err := c.Bind(&reqs); err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: printer.Sprintf("invalid request")})
+	}
+
+	if len(reqs) == 0 {
+		return c.JSON(http.StatusBadRequest, Err{Message: printer.Sprintf("batch must not be empty")})
+	}
+	if len(reqs) > max_batch_size {
+		return c.JSON(http.StatusBadRequest, Err{Message: printer.Sprintf("batch must not have more than %d items", max_batch_size)})
+	}
+
+	default_year := t.defaultTaxYear()
+	calculators := make(map[int]Calculator)
+	not_found := make(map[int]error)
+	res_batch := ResBatch{Results: make([]ResBatchItem, 0, len(reqs))}
+	for i, req := range reqs {
+		item := ResBatchItem{Index: i}
+		if ok, msg := t.validateReq(req, printer); !ok {
+			item.Error = &msg
+			res_batch.Results = append(res_batch.Results, item)
+			continue
 		}
-		res_csv.TotalIncome = income
-		income -= deducate["personal"]
-		for _, de := range []string{"donation", "k-receipt"} {
-			cal, err := t.calDeducation(position, de, v, deducate)
-			if err.Message != "" {
-				return c.JSON(http.StatusBadRequest, err)
-			}
-			income -= cal
+		if req.TaxYear == 0 {
+			req.TaxYear = default_year
 		}
 
-		tax_rate, err := t.info.GetTax()
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to get tax rate: %v", err)})
+		if err, ok := not_found[req.TaxYear]; ok {
+			item.Error = &Err{Message: errMessage(err, req.TaxYear, printer)}
+			res_batch.Results = append(res_batch.Results, item)
+			continue
+		}
+		calculator, err := t.cachedCalculator(calculators, req.TaxYear, lang)
+		if errors.Is(err, ErrTaxYearNotFound) {
+			not_found[req.TaxYear] = err
+			item.Error = &Err{Message: errMessage(err, req.TaxYear, printer)}
+			res_batch.Results = append(res_batch.Results, item)
+			continue
+		} else if err != nil {
+			return c.JSON(errStatus(err), Err{Message: errMessage(err, req.TaxYear, printer)})
+		}
+		if ok, msg := t.validateAllowances(req.Allowances, calculator.AllowanceTypes(), printer); !ok {
+			item.Error = &msg
+			res_batch.Results = append(res_batch.Results, item)
+			continue
+		}
+
+		res := calculator.Calculate(req)
+		item.Result = &res
+		res_batch.Results = append(res_batch.Results, item)
+	}
+
+	return c.JSON(http.StatusOK, res_batch)
+}
+
+func (t Tax) UploadCSVHandler(c echo.Context) error {
+	lang := requestLanguage(c)
+	printer := newPrinter(lang)
+	offers := []string{echo.MIMEApplicationJSON, mime_csv}
+	if c.QueryParam("stream") == "true" {
+		offers = []string{mime_ndjson}
+	}
+	media := negotiate(c, offers...)
+	if media == "" {
+		return c.JSON(http.StatusNotAcceptable, Err{Message: printer.Sprintf("Accept must allow %s", strings.Join(offers, ", "))})
+	}
+
+	files, status, msg := t.csvFiles(c, printer)
+	if form := c.Request().MultipartForm; form != nil {
+		defer form.RemoveAll()
+	}
+	if msg.Message != "" {
+		return c.JSON(status, msg)
+	}
+	defer closeCsvFiles(files)
+
+	upload := &csvUpload{
+		t:           t,
+		files:       files,
+		printer:     printer,
+		lang:        lang,
+		taxYear:     t.defaultTaxYear(),
+		taxLevel:    c.QueryParam("taxLevel") == "true",
+		strict:      c.QueryParam("strict") == "true",
+		calculators: make(map[int]Calculator),
+		notFound:    make(map[int]error),
+	}
+	if media == mime_ndjson {
+		for _, file := range files {
+			file.reader.ReuseRecord = true
 		}
+		return upload.stream(c)
+	}
 
-		var rang_now float64
-		var cal float64
-		for _, tr := range tax_rate {
-			rang_now = tr.Maximum_salary - tr.Minimum_salary
-			if tr.Rate != 0 {
-				rang_now += 1
-			}
-
-			if income <= 0 {
-				cal = 0
-			} else {
-				if rang_now > income || tr.Maximum_salary == 0 {
-					cal = t.calculateTax(income, tr)
-				} else {
-					cal = t.calculateTax(rang_now, tr)
-				}
-				res_csv.Tax += cal
-				income -= rang_now
-			}
+	var rows []csvRow
+	for {
+		row, err := upload.next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return c.JSON(http.StatusBadRequest, Err{Message: printer.Sprintf("failed to read csv")})
 		}
-		if _, ok := position["wht"]; ok {
-			wht, err = strconv.ParseFloat(v[position["wht"]], 64)
-			if err != nil {
-				return c.JSON(http.StatusBadRequest, Err{Message: "invalid field wht"})
-			}
+		if row.err.Reason != "" && upload.stops(row.status) {
+			return c.JSON(row.status, Err{Message: row.err.Reason})
 		}
-		res_csv.Tax -= wht
-		if res_csv.Tax < 0 {
-			res_csv.TaxRefund = res_csv.Tax * -1
-			res_csv.Tax = 0
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return c.JSON(http.StatusBadRequest, Err{Message: printer.Sprintf("invalid csv have not value")})
+	}
+	if media == mime_csv {
+		return upload.table(c, rows)
+	}
+
+	res_all_csv := ResAllCsv{Taxes: []ResCsvTax{}}
+	for _, row := range rows {
+		if row.err.Reason != "" {
+			res_all_csv.Errors = append(res_all_csv.Errors, row.err)
+			res_all_csv.Summary.Rejected++
+		} else {
+			res_all_csv.Taxes = append(res_all_csv.Taxes, row.tax)
+			res_all_csv.Summary.Accepted++
 		}
-		res_all_csv.Taxes = append(res_all_csv.Taxes, res_csv)
 	}
 
 	return c.JSON(http.StatusOK, res_all_csv)
@@ -161,8 +350,14 @@ func (t Tax) TaxDeducateHandler(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, Err{Message: "invalid request"})
 	}
+	if req.TaxYear == 0 {
+		req.TaxYear = t.defaultTaxYear()
+	}
+	if _, err := t.loadTaxRate(req.TaxYear); err != nil {
+		return c.JSON(errStatus(err), Err{Message: err.Error()})
+	}
 
-	personal, err := t.info.GetTaxDeducationByType("Personal")
+	personal, err := t.info.GetTaxDeducationByType(req.TaxYear, "Personal")
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to get personal deduction: %v", err)})
 	}
@@ -172,7 +367,7 @@ func (t Tax) TaxDeducateHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, Err{Message: "Amount should be more than 10,000"})
 	}
 
-	if ok := t.info.SetTaxDeducationByType("Personal", req.Amount); ok != nil {
+	if ok := t.info.SetTaxDeducationByType(req.TaxYear, "Personal", req.Amount); ok != nil {
 		return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to set personal deduction: %v", ok)})
 	}
 
@@ -184,8 +379,14 @@ func (t Tax) TaxDeducateKreceiptHandler(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, Err{Message: "invalid request"})
 	}
+	if req.TaxYear == 0 {
+		req.TaxYear = t.defaultTaxYear()
+	}
+	if _, err := t.loadTaxRate(req.TaxYear); err != nil {
+		return c.JSON(errStatus(err), Err{Message: err.Error()})
+	}
 
-	personal, err := t.info.GetTaxDeducationByType("K-Receipt")
+	personal, err := t.info.GetTaxDeducationByType(req.TaxYear, "K-Receipt")
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to get k-receipt deduction: %v", err)})
 	}
@@ -195,9 +396,31 @@ func (t Tax) TaxDeducateKreceiptHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, Err{Message: "Amount should be more than 0"})
 	}
 
-	if ok := t.info.SetTaxDeducationByType("K-Receipt", req.Amount); ok != nil {
+	if ok := t.info.SetTaxDeducationByType(req.TaxYear, "K-Receipt", req.Amount); ok != nil {
 		return c.JSON(http.StatusInternalServerError, Err{Message: fmt.Sprintf("failed to set k-receipt deduction: %v", ok)})
 	}
 
 	return c.JSON(http.StatusOK, ResKReceiptDeduction{KReceipt: req.Amount})
 }
+
+func errStatus(err error) int {
+	if errors.Is(err, ErrTaxYearNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
+
+// errMessage is the message of err in the language of printer. The errors of
+// the calculator are sent through the catalog, anything else, such as a
+// failing database, keeps its own text.
+func errMessage(err error, tax_year int, printer *message.Printer) string {
+	switch {
+	case errors.Is(err, ErrTaxYearNotFound):
+		return printer.Sprintf("tax year not found: %s", strconv.Itoa(tax_year))
+	case errors.Is(err, ErrTargetUnreachable):
+		return printer.Sprintf("target can not be reached")
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,74 @@
+package tax
+
+import "strings"
+
+// Household compares filing the incomes of req.Taxpayer and req.Spouse in one
+// joint return against each filing their own return.
+//
+// The joint return keeps the expense deduction of each spouse's incomes
+// separate, adds their allowances together type by type so every cap applies
+// to the household, and claims the spouse allowance from tax_deductions on top
+// of the personal deduction. Separate returns are calculated as they are.
+//
+// JointTax and SeparateTax are what the household pays after wht and pnd94,
+// negative for a refund, and the filing with the lower one is recommended.
+// Separate filing wins a tie since it needs no spouse allowance.
+func (c Calculator) Household(req ReqHousehold) ResHousehold {
+	taxpayer, spouse := req.Taxpayer, req.Spouse
+	taxpayer.TaxYear, spouse.TaxYear = req.TaxYear, req.TaxYear
+
+	res := ResHousehold{
+		Separate: ResSeparateFiling{
+			Taxpayer: c.Calculate(taxpayer),
+			Spouse:   c.Calculate(spouse),
+		},
+	}
+	res.SeparateTax = payable(res.Separate.Taxpayer) + payable(res.Separate.Spouse)
+
+	joint := ReqTax{
+		TaxYear:    req.TaxYear,
+		Incomes:    append(append([]Income{}, taxpayer.Incomes...), spouse.Incomes...),
+		Wht:        taxpayer.Wht + spouse.Wht,
+		Pnd94:      taxpayer.Pnd94 + spouse.Pnd94,
+		Allowances: jointAllowances(taxpayer.Allowances, spouse.Allowances),
+	}
+	if deduction, ok := c.Deductions["spouse"]; ok {
+		joint.Allowances = append(joint.Allowances, Allowance{AllowanceType: "spouse", Amount: deduction.Amount})
+	}
+	gross := taxpayer.grossIncome() + spouse.grossIncome()
+	expenses := append(c.expenses(taxpayer.Incomes), c.expenses(spouse.Incomes)...)
+	res.Joint = c.calculate(joint, gross, expenses)
+	res.JointTax = payable(res.Joint)
+
+	res.Recommendation = "separate"
+	res.TaxSaved = res.JointTax - res.SeparateTax
+	if res.JointTax < res.SeparateTax {
+		res.Recommendation = "joint"
+		res.TaxSaved = res.SeparateTax - res.JointTax
+	}
+
+	return res
+}
+
+// jointAllowances adds up the allowances of both spouses by type, in the order
+// the types first appear.
+func jointAllowances(taxpayer, spouse []Allowance) []Allowance {
+	var joint []Allowance
+	index := make(map[string]int)
+	for _, v := range append(append([]Allowance{}, taxpayer...), spouse...) {
+		allowance_type := strings.ToLower(v.AllowanceType)
+		i, ok := index[allowance_type]
+		if !ok {
+			joint = append(joint, Allowance{AllowanceType: allowance_type})
+			i = len(joint) - 1
+			index[allowance_type] = i
+		}
+		joint[i].Amount += v.Amount
+	}
+
+	return joint
+}
+
+func payable(res ResTaxLevel) Money {
+	return res.Tax - res.TaxRefund
+}
@@ -0,0 +1,53 @@
+package tax
+
+import "time"
+
+const (
+	date_layout           = "2006-01-02"
+	late_surcharge_rate   = 1.5
+	late_filing_penalty   = 2000
+	filing_deadline_month = time.March
+	filing_deadline_day   = 31
+)
+
+// dueDate is the last day to file and pay the annual return of tax_year,
+// which is in the Buddhist calendar: 31 March of the following year.
+func dueDate(tax_year int) time.Time {
+	return time.Date(tax_year-543+1, filing_deadline_month, filing_deadline_day, 0, 0, 0, 0, time.UTC)
+}
+
+// lateMonths counts the months or fractions of a month from due to date.
+func lateMonths(due time.Time, date time.Time) int {
+	if !date.After(due) {
+		return 0
+	}
+
+	months := (date.Year()-due.Year())*12 + int(date.Month()-due.Month())
+	if date.Day() > due.Day() {
+		months++
+	}
+
+	return months
+}
+
+// Late adds what paying after the due date costs to the tax of req. The tax
+// still payable after wht and PND94 is the principal, and it is charged a
+// surcharge of 1.5% for every month or fraction of a month it is paid late, up
+// to the principal itself. Filing late is fined a fixed penalty whether or not
+// there is tax to pay.
+func (c Calculator) Late(req ReqTax, filing time.Time, payment time.Time) ResLateTax {
+	due := dueDate(req.TaxYear)
+	res := ResLateTax{
+		DueDate:     due.Format(date_layout),
+		LateMonths:  lateMonths(due, payment),
+		Calculation: c.Calculate(req),
+	}
+	res.Principal = res.Calculation.Tax
+	res.Surcharge = min(res.Principal.Percent(late_surcharge_rate*float64(res.LateMonths)), res.Principal)
+	if filing.After(due) {
+		res.Penalty = Baht(late_filing_penalty)
+	}
+	res.Total = res.Principal + res.Surcharge + res.Penalty
+
+	return res
+}
@@ -0,0 +1,108 @@
+package tax
+
+import (
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// languages are the languages that can be picked with Accept-Language. The
+// first one, language.Und, is used when none of the others match and prints
+// every message as written in the code: English errors and Thai level labels.
+var languages = []language.Tag{language.Und, language.English, language.Thai}
+
+var language_matcher = language.NewMatcher(languages)
+
+var messages = catalog.NewBuilder()
+
+func init() {
+	messages.SetString(language.English, "%d ขึ้นไป", "%d and above")
+
+	for key, msg := range map[string]string{
+		"tax year not found: %s":                                   "ไม่พบปีภาษี %s",
+		"target can not be reached":                                "ไม่สามารถคำนวนรายได้ให้ได้ภาษีตาม targetTax",
+		"Accept must allow %s":                                     "Accept ต้องยอมรับ %s",
+		"totalIncome must not be more than %d":                     "totalIncome ต้องไม่เกิน %d",
+		"pnd94 must not be more than %d":                           "pnd94 ต้องไม่เกิน %d",
+		"Wht must not be more than %d":                             "Wht ต้องไม่เกิน %d",
+		"Amount must not be more than %d":                          "Amount ต้องไม่เกิน %d",
+		"salary and bonus must not be more than %d":                "salary และ bonus ต้องไม่เกิน %d",
+		"ytdIncome must not be more than %d":                       "ytdIncome ต้องไม่เกิน %d",
+		"invalid request":                                          "คำขอไม่ถูกต้อง",
+		"taxYear must be greater than 0":                           "taxYear ต้องมากกว่า 0",
+		"totalIncome must be greater than 0":                       "totalIncome ต้องมากกว่า 0",
+		"totalIncome must equal the sum of incomes":                "totalIncome ต้องเท่ากับผลรวมของ incomes",
+		"Wht must be greater than 0":                               "Wht ต้องมากกว่า 0",
+		"Wht must be less than totalIncome":                        "Wht ต้องน้อยกว่า totalIncome",
+		"pnd94 must be greater than 0":                             "pnd94 ต้องมากกว่า 0",
+		"pnd94 is only credited in the annual return":              "pnd94 ใช้เครดิตได้เฉพาะการคำนวนภาษีทั้งปี",
+		"incomes are required for the half-year return":            "การคำนวนภาษีครึ่งปีต้องระบุ incomes",
+		"half-year return only accepts 40(5) to 40(8) incomes":     "การคำนวนภาษีครึ่งปีรับเฉพาะเงินได้ 40(5) ถึง 40(8)",
+		"Not found incomeType":                                     "ไม่พบ incomeType",
+		"Income amount must be greater than 0":                     "จำนวนเงินได้ต้องมากกว่า 0",
+		"Allowances must be less than or equal to %d":              "Allowances ต้องมีไม่เกิน %d รายการ",
+		"Not found allowanceType":                                  "ไม่พบ allowanceType",
+		"Amount must be greater than 0":                            "Amount ต้องมากกว่า 0",
+		"Duplicate allowanceType":                                  "allowanceType ซ้ำกัน",
+		"Either netIncome or targetTax is required":                "ต้องระบุ netIncome หรือ targetTax อย่างใดอย่างหนึ่ง",
+		"netIncome must be greater than 0":                         "netIncome ต้องมากกว่า 0",
+		"targetTax must be greater than 0":                         "targetTax ต้องมากกว่า 0",
+		"paymentDate must be in YYYY-MM-DD format":                 "paymentDate ต้องอยู่ในรูปแบบ YYYY-MM-DD",
+		"filingDate must be in YYYY-MM-DD format":                  "filingDate ต้องอยู่ในรูปแบบ YYYY-MM-DD",
+		"filingDate must not be after paymentDate":                 "filingDate ต้องไม่อยู่หลัง paymentDate",
+		"brackets are required":                                    "ต้องระบุ brackets",
+		"brackets must start at 0":                                 "brackets ต้องเริ่มที่ 0",
+		"bracket rate must be between 0 and 100":                   "rate ของ bracket ต้องอยู่ระหว่าง 0 ถึง 100",
+		"only the last bracket can have no maximumSalary":          "เฉพาะ bracket สุดท้ายที่ไม่ต้องระบุ maximumSalary",
+		"bracket maximumSalary must be greater than minimumSalary": "maximumSalary ของ bracket ต้องมากกว่า minimumSalary",
+		"brackets must not overlap":                                "brackets ต้องไม่ซ้อนกัน",
+		"brackets must not have gaps":                              "brackets ต้องต่อเนื่องกัน",
+		"last bracket must have no maximumSalary":                  "bracket สุดท้ายต้องไม่มี maximumSalary",
+		"deduction type is required":                               "ต้องระบุ type ของค่าลดหย่อน",
+		"Duplicate deduction type":                                 "type ของค่าลดหย่อนซ้ำกัน",
+		"spouse allowance is applied by joint filing":              "ค่าลดหย่อนคู่สมรสใช้ได้เฉพาะการยื่นรวม",
+		"month must be between 1 and 12":                           "month ต้องอยู่ระหว่าง 1 ถึง 12",
+		"salary and bonus must be greater than 0":                  "salary และ bonus ต้องมากกว่า 0",
+		"salary or bonus is required":                              "ต้องระบุ salary หรือ bonus",
+		"ytdIncome and ytdWht must be greater than 0":              "ytdIncome และ ytdWht ต้องมากกว่า 0",
+		"ytdIncome and ytdWht must be 0 in month 1":                "ytdIncome และ ytdWht ต้องเป็น 0 ในเดือนที่ 1",
+		"ytdWht must be less than ytdIncome":                       "ytdWht ต้องน้อยกว่า ytdIncome",
+		"batch must not be empty":                                  "batch ต้องมีอย่างน้อย 1 รายการ",
+		"batch must not have more than %d items":                   "batch ต้องมีไม่เกิน %d รายการ",
+		"failed to read csv":                                       "อ่านไฟล์ csv ไม่สำเร็จ",
+		"failed to get deduction":                                  "ดึงข้อมูลค่าลดหย่อนไม่สำเร็จ",
+		"invalid csv":                                              "csv ไม่ถูกต้อง",
+		"invalid csv have not totalIncome":                         "csv ไม่มีคอลัมน์ totalIncome",
+		"invalid csv have not value":                               "csv ไม่มีข้อมูล",
+		"invalid multipart form":                                   "multipart form ไม่ถูกต้อง",
+		"taxFile is required":                                      "ต้องแนบไฟล์ taxFile",
+		"taxFile %s must not be larger than %d MB":                 "ไฟล์ taxFile %s ต้องมีขนาดไม่เกิน %d MB",
+		"upload must not be larger than %d MB":                     "ไฟล์ที่อัปโหลดรวมกันต้องมีขนาดไม่เกิน %d MB",
+		"unsupported charset %s":                                   "ไม่รองรับ charset %s",
+		"wrong number of fields":                                   "จำนวนคอลัมน์ไม่ถูกต้อง",
+		"invalid field %s":                                         "ข้อมูลในคอลัมน์ %s ไม่ถูกต้อง",
+	} {
+		messages.SetString(language.Thai, key, msg)
+	}
+}
+
+// requestLanguage picks th or en from the Accept-Language header of the
+// request, or language.Und when the header asks for neither.
+func requestLanguage(c echo.Context) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+	if err != nil {
+		return language.Und
+	}
+
+	_, i, confidence := language_matcher.Match(tags...)
+	if confidence == language.No {
+		return language.Und
+	}
+
+	return languages[i]
+}
+
+func newPrinter(tag language.Tag) *message.Printer {
+	return message.NewPrinter(tag, message.Catalog(messages))
+}
@@ -0,0 +1,226 @@
+package tax
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount in satang (1/100 baht).
+//
+// Adding and subtracting Money is always exact. The only place a result is
+// rounded is Percent, which rounds half away from zero to the nearest satang,
+// so every tax slice is rounded once and the total is the exact sum of the
+// slices. On the wire Money is a plain JSON number in baht, e.g. 29000 or
+// 1234.5.
+type Money int64
+
+var errInvalidMoney = errors.New("invalid money")
+
+// max_whole_baht is the largest whole part ParseMoney takes, so that adding
+// the satang, rounded up to a full baht, still fits in Money.
+const max_whole_baht = (math.MaxInt64 - 100) / 100
+
+// max_amount_baht is the most baht a request may send in one amount, or in
+// all its incomes together, so that the sums and products of the calculation
+// stay far inside Money.
+const max_amount_baht = 1_000_000_000_000
+
+// Baht returns b whole baht as Money.
+func Baht(b int64) Money {
+	return Money(b * 100)
+}
+
+// max_money_exponent is the largest exponent, either way, ParseMoney takes.
+// Anything past it is too large for Money or rounds to 0 satang anyway.
+const max_money_exponent = 64
+
+// ParseMoney parses a decimal baht string such as "1234", "1234.5", "-0.25"
+// or, the way JSON may write numbers, "5e5" or "1.5E+3". Digits after the
+// second decimal place are rounded half away from zero.
+func ParseMoney(s string) (Money, error) {
+	str := strings.TrimSpace(s)
+	negative := strings.HasPrefix(str, "-")
+	str = strings.TrimPrefix(strings.TrimPrefix(str, "-"), "+")
+
+	mantissa, exponent, scientific := strings.Cut(strings.ToLower(str), "e")
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	if whole == "" && frac == "" {
+		return 0, errInvalidMoney
+	}
+	if scientific {
+		exp, err := strconv.Atoi(exponent)
+		if err != nil || exp < -max_money_exponent || exp > max_money_exponent {
+			return 0, errInvalidMoney
+		}
+		whole, frac = shiftPoint(whole, frac, exp)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if strings.ContainsAny(whole+frac, "+-eE") {
+		return 0, errInvalidMoney
+	}
+
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w > max_whole_baht {
+		return 0, errInvalidMoney
+	}
+	var satang int64
+	for i := 0; i < 3; i++ {
+		digit := int64(0)
+		if i < len(frac) {
+			if frac[i] < '0' || frac[i] > '9' {
+				return 0, errInvalidMoney
+			}
+			digit = int64(frac[i] - '0')
+		}
+		if i < 2 {
+			satang = satang*10 + digit
+		} else if digit >= 5 {
+			satang++
+		}
+	}
+	for i := 3; i < len(frac); i++ {
+		if frac[i] < '0' || frac[i] > '9' {
+			return 0, errInvalidMoney
+		}
+	}
+
+	m := Money(w*100 + satang)
+	if negative {
+		m = -m
+	}
+
+	return m, nil
+}
+
+// shiftPoint moves the decimal point between whole and frac exp places to
+// the right, or to the left when exp is negative, padding with zeros.
+func shiftPoint(whole string, frac string, exp int) (string, string) {
+	digits := whole + frac
+	point := len(whole) + exp
+	if point < 0 {
+		digits = strings.Repeat("0", -point) + digits
+		point = 0
+	}
+	if point > len(digits) {
+		digits += strings.Repeat("0", point-len(digits))
+	}
+
+	return digits[:point], digits[point:]
+}
+
+// Percent returns rate percent of m. The rate is taken to two decimal places
+// (0.01%) and the result is rounded half away from zero to the nearest satang.
+// The product is worked out in 128 bits, so it never overflows; a result too
+// large for Money is capped at the largest Money of its sign.
+func (m Money) Percent(rate float64) Money {
+	basis := int64(rate*100 + 0.5)
+	if rate < 0 {
+		basis = int64(rate*100 - 0.5)
+	}
+
+	hi, lo := bits.Mul64(absUint64(int64(m)), absUint64(basis))
+	result := uint64(math.MaxInt64)
+	if hi < 10000 {
+		quotient, remainder := bits.Div64(hi, lo, 10000)
+		if remainder >= 5000 {
+			quotient++
+		}
+		result = min(quotient, math.MaxInt64)
+	}
+
+	if (m < 0) != (basis < 0) {
+		return Money(-int64(result))
+	}
+
+	return Money(result)
+}
+
+func absUint64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+
+	return uint64(v)
+}
+
+// WholeBaht returns the amount truncated to whole baht.
+func (m Money) WholeBaht() int64 {
+	return int64(m) / 100
+}
+
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	str := m.String()
+	str = strings.TrimRight(strings.TrimRight(str, "0"), ".")
+
+	return []byte(str), nil
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	str := string(b)
+	if str == "null" {
+		return nil
+	}
+
+	v, err := ParseMoney(str)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidMoney, str)
+	}
+	*m = v
+
+	return nil
+}
+
+// Scan lets database/sql read INT and NUMERIC baht columns into Money.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Baht(v)
+	case float64:
+		parsed, err := ParseMoney(strconv.FormatFloat(v, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+
+	return nil
+}
+
+// Value writes Money as a decimal baht string.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
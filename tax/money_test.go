@@ -0,0 +1,105 @@
+//go:build unit
+
+package tax
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("Test parse money", func(t *testing.T) {
+		tests := []struct {
+			str  string
+			want Money
+		}{
+			{"500000", Baht(500000)},
+			{"500000.0", Baht(500000)},
+			{"0.1", 10},
+			{"1234.56", 123456},
+			{"1234.565", 123457},
+			{"1234.564", 123456},
+			{"-0.25", -25},
+			{".5", 50},
+			{"92233720368547757.99", Money(math.MaxInt64 - 8)},
+			{"5e5", Baht(500000)},
+			{"1E+6", Baht(1000000)},
+			{"1.2345e2", 12345},
+			{"-25E-2", -25},
+			{"1e-3", 0},
+			{"5e-3", 1},
+		}
+
+		for _, tt := range tests {
+			got, err := ParseMoney(tt.str)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.str, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s: got: %v, want: %v", tt.str, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("Test parse invalid money", func(t *testing.T) {
+		for _, str := range []string{"", ".", "abc", "e5", "1e", "1e5e5", "1e+-5", "1e100", "1e20", "1.2.3", "--1", "12,000", "200000000000000000", "92233720368547758"} {
+			if _, err := ParseMoney(str); err == nil {
+				t.Errorf("%s: want error", str)
+			}
+		}
+	})
+
+	t.Run("Test percent round half away from zero", func(t *testing.T) {
+		tests := []struct {
+			money Money
+			rate  float64
+			want  Money
+		}{
+			{Baht(290000), 10, Baht(29000)},
+			{333, 10, 33},
+			{335, 10, 34},
+			{-335, 10, -34},
+			{Baht(1000), 0.5, Baht(5)},
+			{1, 15, 0},
+			{Baht(1e14), 35, Baht(35e12)},
+			{-Baht(1e14), 35, -Baht(35e12)},
+			{math.MaxInt64, 200, math.MaxInt64},
+			{math.MinInt64, 100, -math.MaxInt64},
+		}
+
+		for _, tt := range tests {
+			if got := tt.money.Percent(tt.rate); got != tt.want {
+				t.Errorf("%v * %v%%: got: %v, want: %v", tt.money, tt.rate, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("Test json is numeric baht", func(t *testing.T) {
+		got, _ := json.Marshal(ReqTax{TotalIncome: Baht(500000), Wht: 2900050, Allowances: []Allowance{{AllowanceType: "donation", Amount: 1}}})
+		want := `{"taxYear":0,"totalIncome":500000,"wht":29000.5,"Allowances":[{"allowanceType":"donation","amount":0.01}]}`
+		if string(got) != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+
+		var req ReqTax
+		if err := json.Unmarshal([]byte(`{"totalIncome":5e5,"wht":1E+3}`), &req); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if req.TotalIncome != Baht(500000) || req.Wht != Baht(1000) {
+			t.Errorf("got: %v, %v, want: 500000.00, 1000.00", req.TotalIncome, req.Wht)
+		}
+
+		req = ReqTax{}
+		if err := json.Unmarshal([]byte(`{"totalIncome":0.1,"wht":0.2}`), &req); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if req.TotalIncome+req.Wht != 30 {
+			t.Errorf("got: %v, want: %v", req.TotalIncome+req.Wht, Money(30))
+		}
+
+		if err := json.Unmarshal([]byte(`{"totalIncome":200000000000000000}`), &req); err == nil {
+			t.Errorf("want error for totalIncome that does not fit in Money")
+		}
+	})
+}
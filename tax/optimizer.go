@@ -0,0 +1,108 @@
+package tax
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+var optimize_types = []string{"donation", "k-receipt"}
+
+// Optimize recommends how much more donation and k-receipt spending still
+// lowers the tax of req, up to each deduction's cap. The cap is the one
+// capAllowance applies, so a cap_percent counts on the income left after the
+// other deductions. Types are filled in the order applyAllowances deducts
+// them, flat caps before percent caps, and every recommendation already counts
+// the ones before it. Spending that would only push income further into the 0%
+// bracket is not recommended. Wht plays no part in the recommendation.
+func (c Calculator) Optimize(req ReqTax) ResOptimize {
+	allowances := make([]Allowance, 0, len(req.Allowances)+len(optimize_types))
+	for _, v := range req.Allowances {
+		allowances = append(allowances, Allowance{AllowanceType: strings.ToLower(v.AllowanceType), Amount: v.Amount})
+	}
+	calculate := func() ResTaxLevel {
+		return c.Calculate(ReqTax{TotalIncome: req.TotalIncome, Incomes: req.Incomes, Allowances: allowances})
+	}
+
+	types := slices.Clone(optimize_types)
+	slices.SortStableFunc(types, func(a, b string) int {
+		percent_a, percent_b := c.Deductions[a].Cap_percent != 0, c.Deductions[b].Cap_percent != 0
+		if percent_a != percent_b {
+			if percent_a {
+				return 1
+			}
+			return -1
+		}
+		if percent_a {
+			return c.Deductions[a].Apply_order - c.Deductions[b].Apply_order
+		}
+		return 0
+	})
+
+	res := ResOptimize{Tax: calculate().Tax}
+	for _, allowance_type := range types {
+		i := -1
+		for j, v := range allowances {
+			if v.AllowanceType == allowance_type {
+				i = j
+			}
+		}
+		if i < 0 {
+			allowances = append(allowances, Allowance{AllowanceType: allowance_type})
+			i = len(allowances) - 1
+		}
+
+		limit := c.allowanceLimit(ReqTax{TotalIncome: req.TotalIncome, Incomes: req.Incomes, Allowances: allowances}, allowance_type)
+		current := min(allowances[i].Amount, limit)
+		allowances[i].Amount = current
+		before := calculate()
+
+		allowances[i].Amount = limit
+		best := calculate().Tax
+
+		// Search in whole baht so satang rounding of the brackets does not
+		// turn into recommendations like 99,999.96.
+		lo, hi := int64(0), int64(limit-current+Baht(1)-1)/100
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			allowances[i].Amount = min(current+Baht(mid), limit)
+			if calculate().Tax <= best {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		allowances[i].Amount = min(current+Baht(hi), limit)
+
+		recommendation := Recommendation{
+			AllowanceType:  allowance_type,
+			Current:        current,
+			Limit:          limit,
+			Additional:     allowances[i].Amount - current,
+			TaxSaved:       before.Tax - best,
+			MarginalSaving: before.MarginalRate / 100,
+		}
+		if before.TaxableIncome <= 0 {
+			recommendation.MarginalSaving = 0
+		}
+		if recommendation.Additional > 0 {
+			recommendation.SavedPerBaht = math.Round(float64(recommendation.TaxSaved)*10000/float64(recommendation.Additional)) / 10000
+		}
+		res.Recommendations = append(res.Recommendations, recommendation)
+	}
+	res.OptimizedTax = calculate().Tax
+
+	return res
+}
+
+// allowanceLimit returns the cap of allowance_type in req as the calculation
+// applies it, after the deductions before it and any deduction group.
+func (c Calculator) allowanceLimit(req ReqTax, allowance_type string) Money {
+	for _, v := range c.Explain(req).Trace {
+		if v.Step == "deduction" && v.Name == allowance_type {
+			return max(v.Limit, 0)
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,66 @@
+package tax
+
+import "errors"
+
+// ErrTargetUnreachable is returned when no income up to maxReverseIncome
+// produces the requested net income or tax.
+var ErrTargetUnreachable = errors.New("target can not be reached")
+
+var maxReverseIncome = Baht(1_000_000_000_000)
+
+// Reverse finds the smallest totalIncome whose calculation reaches the target
+// of req. For netIncome the target is totalIncome minus tax before wht, for
+// targetTax it is the tax field of the result, i.e. after wht.
+//
+// Both figures only grow with income, so the answer is found by binary search
+// using Calculate itself, which keeps it consistent with whatever brackets and
+// deductions the calculator was loaded with. Like Optimize it searches in whole
+// baht, so satang rounding of the brackets does not turn into answers like
+// 509,999.95.
+func (c Calculator) Reverse(req ReqReverseTax) (ResReverseTax, error) {
+	calculate := func(income Money) (ResTaxLevel, Money) {
+		res := c.Calculate(ReqTax{
+			TaxYear:     req.TaxYear,
+			TotalIncome: income,
+			Wht:         req.Wht,
+			Allowances:  req.Allowances,
+		})
+		net := income - (res.Tax - res.TaxRefund + req.Wht)
+
+		return res, net
+	}
+	reached := func(income Money) bool {
+		res, net := calculate(income)
+		if req.NetIncome != nil {
+			return net >= *req.NetIncome
+		}
+
+		return res.Tax >= *req.TargetTax
+	}
+
+	hi := int64(1)
+	for !reached(Baht(hi)) {
+		if Baht(hi) >= maxReverseIncome {
+			return ResReverseTax{}, ErrTargetUnreachable
+		}
+		hi *= 2
+	}
+
+	var lo int64
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if reached(Baht(mid)) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	res, net := calculate(Baht(hi))
+
+	return ResReverseTax{
+		TotalIncome: Baht(hi),
+		NetIncome:   net,
+		ResTaxLevel: res,
+	}, nil
+}
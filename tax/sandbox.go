@@ -0,0 +1,35 @@
+package tax
+
+import "strings"
+
+// Sandbox returns a copy of c that taxes with brackets instead of the tax
+// rates of the tax year, and with the cap of every deduction in overrides
+// replaced. An override of a type that is not in tax_deductions adds it as a
+// flat capped allowance. Nothing is written back to the database.
+func (c Calculator) Sandbox(brackets []Bracket, overrides []DeductionOverride) Calculator {
+	c.Rates = make([]DB, 0, len(brackets))
+	for _, v := range brackets {
+		c.Rates = append(c.Rates, DB{
+			Minimum_salary: v.MinimumSalary,
+			Maximum_salary: v.MaximumSalary,
+			Rate:           v.Rate,
+		})
+	}
+
+	deductions := make(map[string]DbDeduction, len(c.Deductions)+len(overrides))
+	for k, v := range c.Deductions {
+		deductions[k] = v
+	}
+	c.Deductions = deductions
+	for _, v := range overrides {
+		deduction_type := strings.ToLower(v.Type)
+		deduction, ok := c.Deductions[deduction_type]
+		if !ok {
+			deduction = DbDeduction{Type: v.Type}
+		}
+		deduction.Amount = v.Amount
+		c.Deductions[deduction_type] = deduction
+	}
+
+	return c
+}
@@ -1,67 +1,307 @@
 package tax
 
 type Allowance struct {
-	AllowanceType string  `json:"allowanceType"`
-	Amount        float64 `json:"amount"`
+	AllowanceType string `json:"allowanceType"`
+	Amount        Money  `json:"amount"`
+}
+
+type Income struct {
+	IncomeType string `json:"incomeType"`
+	Amount     Money  `json:"amount"`
 }
 
 type ReqTax struct {
-	TotalIncome float64 `json:"totalIncome"`
-	Wht         float64 `json:"wht"`
+	TaxYear     int      `json:"taxYear"`
+	TotalIncome Money    `json:"totalIncome"`
+	Incomes     []Income `json:"incomes,omitempty"`
+	Wht         Money    `json:"wht"`
+	Pnd94       Money    `json:"pnd94,omitempty"`
 	Allowances  []Allowance
 }
 
 type TaxLevel struct {
-	Level string  `json:"level"`
-	Tax   float64 `json:"tax"`
+	Level string `json:"level"`
+	Tax   Money  `json:"tax"`
+}
+
+type AppliedDeduction struct {
+	Type      string `json:"type"`
+	Group     string `json:"group,omitempty"`
+	Requested Money  `json:"requested"`
+	Applied   Money  `json:"applied"`
+}
+
+type AppliedGroup struct {
+	Group   string `json:"group"`
+	Limit   Money  `json:"limit"`
+	Applied Money  `json:"applied"`
+}
+
+type AppliedExpense struct {
+	ExpenseGroup string `json:"expenseGroup"`
+	Income       Money  `json:"income"`
+	Expense      Money  `json:"expense"`
+}
+
+type TaxMethod struct {
+	Method         string `json:"method"`
+	ProgressiveTax Money  `json:"progressiveTax"`
+	MinimumTax     Money  `json:"minimumTax"`
+}
+
+// TaxCredit is the tax already paid during the year that is credited against
+// the tax of the annual return.
+type TaxCredit struct {
+	Wht   Money `json:"wht"`
+	Pnd94 Money `json:"pnd94"`
+	Total Money `json:"total"`
+}
+
+type TaxDetail struct {
+	TaxMethod       *TaxMethod         `json:"taxMethod,omitempty"`
+	TaxCredit       *TaxCredit         `json:"taxCredit,omitempty"`
+	Expenses        []AppliedExpense   `json:"expenses,omitempty"`
+	TaxableIncome   Money              `json:"taxableIncome"`
+	Deductions      []AppliedDeduction `json:"deductions"`
+	DeductionGroups []AppliedGroup     `json:"deductionGroups,omitempty"`
+	MarginalLevel   string             `json:"marginalLevel"`
+	MarginalRate    float64            `json:"marginalRate"`
+	EffectiveRate   float64            `json:"effectiveRate"`
+}
+
+// TraceStep is one step of an explained calculation. Amount is what the step
+// adds or deducts and Balance the running figure after it: the income for
+// income and deduction steps, the tax for bracket and later steps.
+type TraceStep struct {
+	Step        string  `json:"step"`
+	Name        string  `json:"name,omitempty"`
+	Requested   Money   `json:"requested,omitempty"`
+	Limit       Money   `json:"limit,omitempty"`
+	LimitSource string  `json:"limitSource,omitempty"`
+	Rate        float64 `json:"rate,omitempty"`
+	Income      Money   `json:"income,omitempty"`
+	Amount      Money   `json:"amount"`
+	Balance     Money   `json:"balance"`
 }
 
 type ResTaxLevel struct {
-	Tax       float64 `json:"tax"`
-	TaxRefund float64 `json:"taxRefund"`
-	TaxLevel  []TaxLevel
+	Tax       Money `json:"tax"`
+	TaxRefund Money `json:"taxRefund"`
+	TaxDetail
+	TaxLevel []TaxLevel
+	Trace    []TraceStep `json:"trace,omitempty"`
+}
+
+type ReqReverseTax struct {
+	TaxYear    int         `json:"taxYear"`
+	NetIncome  *Money      `json:"netIncome"`
+	TargetTax  *Money      `json:"targetTax"`
+	Wht        Money       `json:"wht"`
+	Allowances []Allowance `json:"allowances"`
+}
+
+type ResReverseTax struct {
+	TotalIncome Money `json:"totalIncome"`
+	NetIncome   Money `json:"netIncome"`
+	ResTaxLevel
+}
+
+type Recommendation struct {
+	AllowanceType  string  `json:"allowanceType"`
+	Current        Money   `json:"current"`
+	Limit          Money   `json:"limit"`
+	Additional     Money   `json:"additional"`
+	TaxSaved       Money   `json:"taxSaved"`
+	SavedPerBaht   float64 `json:"savedPerBaht"`
+	MarginalSaving float64 `json:"marginalSaving"`
+}
+
+type ResOptimize struct {
+	Tax             Money            `json:"tax"`
+	OptimizedTax    Money            `json:"optimizedTax"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+type ReqWithholding struct {
+	TaxYear    int         `json:"taxYear"`
+	Month      int         `json:"month"`
+	Salary     Money       `json:"salary"`
+	Bonus      Money       `json:"bonus"`
+	YtdIncome  Money       `json:"ytdIncome"`
+	YtdWht     Money       `json:"ytdWht"`
+	Allowances []Allowance `json:"allowances"`
+}
+
+type ResWithholding struct {
+	Month             int   `json:"month"`
+	RemainingMonths   int   `json:"remainingMonths"`
+	AnnualIncome      Money `json:"annualIncome"`
+	AnnualTax         Money `json:"annualTax"`
+	YtdWht            Money `json:"ytdWht"`
+	SalaryWithholding Money `json:"salaryWithholding"`
+	BonusWithholding  Money `json:"bonusWithholding"`
+	Withholding       Money `json:"withholding"`
+}
+
+type ReqHousehold struct {
+	TaxYear  int    `json:"taxYear"`
+	Taxpayer ReqTax `json:"taxpayer"`
+	Spouse   ReqTax `json:"spouse"`
+}
+
+type ResSeparateFiling struct {
+	Taxpayer ResTaxLevel `json:"taxpayer"`
+	Spouse   ResTaxLevel `json:"spouse"`
+}
+
+type ResHousehold struct {
+	JointTax       Money             `json:"jointTax"`
+	SeparateTax    Money             `json:"separateTax"`
+	Recommendation string            `json:"recommendation"`
+	TaxSaved       Money             `json:"taxSaved"`
+	Joint          ResTaxLevel       `json:"joint"`
+	Separate       ResSeparateFiling `json:"separate"`
+}
+
+type ReqLateTax struct {
+	ReqTax
+	FilingDate  string `json:"filingDate"`
+	PaymentDate string `json:"paymentDate"`
+}
+
+type ResLateTax struct {
+	DueDate     string      `json:"dueDate"`
+	LateMonths  int         `json:"lateMonths"`
+	Principal   Money       `json:"principal"`
+	Surcharge   Money       `json:"surcharge"`
+	Penalty     Money       `json:"penalty"`
+	Total       Money       `json:"total"`
+	Calculation ResTaxLevel `json:"calculation"`
+}
+
+type Bracket struct {
+	MinimumSalary Money   `json:"minimumSalary"`
+	MaximumSalary Money   `json:"maximumSalary"`
+	Rate          float64 `json:"rate"`
+}
+
+type DeductionOverride struct {
+	Type   string `json:"type"`
+	Amount Money  `json:"amount"`
+}
+
+type ReqSandbox struct {
+	ReqTax
+	Brackets   []Bracket           `json:"brackets"`
+	Deductions []DeductionOverride `json:"deductions"`
 }
 
 type ReqAmount struct {
-	Amount float64 `json:"amount"`
+	TaxYear int   `json:"taxYear"`
+	Amount  Money `json:"amount"`
 }
 
 type ResPersonalDeduction struct {
-	PersonalDeduction float64 `json:"personalDeduction"`
+	PersonalDeduction Money `json:"personalDeduction"`
 }
 
 type ResKReceiptDeduction struct {
-	KReceipt float64 `json:"kReceipt"`
+	KReceipt Money `json:"kReceipt"`
 }
 
+// ResCsvTax is the result of one accepted row of an uploaded csv. File and
+// Row name the row the same way CsvError does for a rejected one.
 type ResCsvTax struct {
-	TotalIncome float64 `json:"totalIncome"`
-	Tax         float64 `json:"tax"`
-	TaxRefund   float64 `json:"taxRefund"`
+	File        string `json:"file,omitempty"`
+	Row         int    `json:"row"`
+	TotalIncome Money  `json:"totalIncome"`
+	Tax         Money  `json:"tax"`
+	TaxRefund   Money  `json:"taxRefund"`
+	TaxDetail
+	TaxLevel []TaxLevel `json:"TaxLevel,omitempty"`
+}
+
+type ResBatchItem struct {
+	Index  int          `json:"index"`
+	Result *ResTaxLevel `json:"result,omitempty"`
+	Error  *Err         `json:"error,omitempty"`
+}
+
+type ResBatch struct {
+	Results []ResBatchItem `json:"results"`
+}
+
+// CsvError tells which row of an uploaded csv was rejected and why. File is
+// the name of the uploaded file, empty for a raw body, and Row the line of the
+// file the row starts on, counting the header as line 1.
+type CsvError struct {
+	File   string `json:"file,omitempty"`
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type CsvSummary struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
 }
 
 type ResAllCsv struct {
-	Taxes []ResCsvTax `json:"taxes"`
+	Taxes   []ResCsvTax `json:"taxes"`
+	Errors  []CsvError  `json:"errors,omitempty"`
+	Summary CsvSummary  `json:"summary"`
 }
 
 type DB struct {
 	ID             int     `postgres:"id"`
-	Minimum_salary float64 `postgres:"minimum_salary"`
-	Maximum_salary float64 `postgres:"maximum_salary|NULL"`
+	Tax_year       int     `postgres:"tax_year"`
+	Minimum_salary Money   `postgres:"minimum_salary"`
+	Maximum_salary Money   `postgres:"maximum_salary|NULL"`
 	Rate           float64 `postgres:"rate"`
 	Created_at     string  `postgres:"created_at"`
 }
 
+// DbDeduction is a row of tax_deductions. Amount caps the deduction. When
+// Cap_percent is set the deduction is also capped at that percentage of the
+// income left after the other deductions, and an Amount of 0 then means no
+// flat cap. Requested amounts are multiplied by Multiplier (0 counts as 1)
+// before capping. Deductions with the same Group_name also share the cap of
+// that DbDeductionGroup.
 type DbDeduction struct {
 	ID             int     `postgres:"id"`
+	Tax_year       int     `postgres:"tax_year"`
 	Type           string  `postgres:"deducation_type"`
-	Minimum_amount float64 `postgres:"minimum_amount"`
-	Maximum_amount float64 `postgres:"maximum_amount"`
-	Amount         float64 `postgres:"amount"`
+	Minimum_amount Money   `postgres:"minimum_amount"`
+	Maximum_amount Money   `postgres:"maximum_amount"`
+	Amount         Money   `postgres:"amount"`
+	Cap_percent    float64 `postgres:"cap_percent"`
+	Multiplier     float64 `postgres:"multiplier"`
+	Apply_order    int     `postgres:"apply_order"`
+	Group_name     string  `postgres:"group_name"`
 	Created_at     string  `postgres:"created_at"`
 	Updated_at     string  `postgres:"updated_at"`
 }
 
+// DbDeductionGroup is a row of deduction_groups, the combined cap of every
+// tax_deductions row with the same group_name.
+type DbDeductionGroup struct {
+	ID         int    `postgres:"id"`
+	Tax_year   int    `postgres:"tax_year"`
+	Group_name string `postgres:"group_name"`
+	Amount     Money  `postgres:"amount"`
+	Created_at string `postgres:"created_at"`
+}
+
+type DbExpenseRule struct {
+	ID             int     `postgres:"id"`
+	Tax_year       int     `postgres:"tax_year"`
+	Income_type    string  `postgres:"income_type"`
+	Expense_group  string  `postgres:"expense_group"`
+	Rate           float64 `postgres:"rate"`
+	Maximum_amount Money   `postgres:"maximum_amount|NULL"`
+	Created_at     string  `postgres:"created_at"`
+}
+
 type Tax struct {
 	info InfoTax
 }
@@ -71,9 +311,14 @@ type Err struct {
 }
 
 type InfoTax interface {
-	GetTax() ([]DB, error)
-	GetTaxDeducationByType(deducation_type string) (DbDeduction, error)
-	SetTaxDeducationByType(deducation_type string, amount float64) error
+	GetTax(tax_year int) ([]DB, error)
+	GetTaxYears() ([]int, error)
+	GetTaxDeducations(tax_year int) ([]DbDeduction, error)
+	GetDeducationTypes() ([]string, error)
+	GetTaxDeducationByType(tax_year int, deducation_type string) (DbDeduction, error)
+	SetTaxDeducationByType(tax_year int, deducation_type string, amount Money) error
+	GetDeductionGroups(tax_year int) ([]DbDeductionGroup, error)
+	GetExpenseRules(tax_year int) ([]DbExpenseRule, error)
 }
 
 func New(info InfoTax) Tax {
@@ -0,0 +1,162 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAllowanceRegistry(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+			{
+				Type:   "K-Receipt",
+				Amount: Baht(50000),
+			},
+			{
+				Type:   "Spouse",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Life-Insurance",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	t.Run("Test allowance types from tax_deductions", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(500000),
+			Allowances: []Allowance{
+				{
+					AllowanceType: "spouse",
+					Amount:        Baht(60000),
+				},
+				{
+					AllowanceType: "Life-Insurance",
+					Amount:        Baht(150000),
+				},
+				{
+					AllowanceType: "donation",
+					Amount:        Baht(10000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// 500,000 - 60,000 - 60,000 - 100,000 - 10,000 = 270,000
+		if got.TaxableIncome != Baht(270000) || got.Tax != Baht(12000) {
+			t.Errorf("got: %v, want taxableIncome: 270000, tax: 12000", got)
+		}
+	})
+
+	t.Run("Test allowance type not in tax_deductions", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(500000),
+			Allowances: []Allowance{
+				{
+					AllowanceType: "rmf",
+					Amount:        Baht(60000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "Not found allowanceType"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test csv allowance column from tax_deductions", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "life-insurance", "spouse"})
+		writer.Write([]string{"500000", "150000", "60000"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResAllCsv
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		want := []AppliedDeduction{
+			{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+			{Type: "life-insurance", Requested: Baht(150000), Applied: Baht(100000)},
+			{Type: "spouse", Requested: Baht(60000), Applied: Baht(60000)},
+		}
+		if len(got.Taxes) != 1 || !reflect.DeepEqual(got.Taxes[0].Deductions, want) {
+			t.Errorf("got: %v, want: %v", got.Taxes, want)
+		}
+	})
+}
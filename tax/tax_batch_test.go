@@ -0,0 +1,154 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type countingTax struct {
+	MockTax
+	getTax      int
+	getTaxYears int
+}
+
+func (m *countingTax) GetTax(tax_year int) ([]DB, error) {
+	m.getTax++
+
+	return m.MockTax.GetTax(tax_year)
+}
+
+func (m *countingTax) GetTaxYears() ([]int, error) {
+	m.getTaxYears++
+
+	return m.MockTax.GetTaxYears()
+}
+
+func TestBatchTaxHandler(t *testing.T) {
+	t.Run("Test per-item results and errors", func(t *testing.T) {
+		e := echo.New()
+		MockReq := []ReqTax{
+			{TotalIncome: Baht(500000)},
+			{TotalIncome: 0},
+			{
+				TotalIncome: Baht(1000000),
+				Allowances: []Allowance{
+					{
+						AllowanceType: "donation",
+						Amount:        Baht(200000),
+					},
+				},
+			},
+			{TaxYear: 2500, TotalIncome: Baht(500000)},
+			{
+				TotalIncome: Baht(500000),
+				Allowances: []Allowance{
+					{
+						AllowanceType: "rmf",
+						Amount:        Baht(1000),
+					},
+				},
+			},
+			{TaxYear: 2500, TotalIncome: Baht(600000)},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/batch", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := countingTax{
+			MockTax: MockTax{
+				dbDeduction: []DbDeduction{
+					{
+						Type:   "Personal",
+						Amount: Baht(60000),
+					},
+					{
+						Type:   "Donation",
+						Amount: Baht(100000),
+					},
+				},
+				taxYears: []int{currentTaxYear()},
+			},
+		}
+
+		handler := New(&mock)
+		handler.BatchTaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResBatch
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		if len(got.Results) != len(MockReq) {
+			t.Fatalf("got: %v results, want: %v", len(got.Results), len(MockReq))
+		}
+
+		taxes := map[int]Money{0: Baht(29000), 2: Baht(86000)}
+		for i, v := range taxes {
+			if got.Results[i].Index != i || got.Results[i].Result == nil || got.Results[i].Result.Tax != v {
+				t.Errorf("got: %v, want tax: %v", got.Results[i], v)
+			}
+		}
+
+		errs := map[int]Err{
+			1: {Message: "totalIncome must be greater than 0"},
+			3: {Message: "tax year not found: 2500"},
+			4: {Message: "Not found allowanceType"},
+			5: {Message: "tax year not found: 2500"},
+		}
+		for i, v := range errs {
+			if got.Results[i].Index != i || got.Results[i].Error == nil || !reflect.DeepEqual(*got.Results[i].Error, v) {
+				t.Errorf("got: %v, want error: %v", got.Results[i], v)
+			}
+		}
+
+		if mock.getTax != 2 {
+			t.Errorf("got: %v GetTax calls, want: 2", mock.getTax)
+		}
+		if mock.getTaxYears != 1 {
+			t.Errorf("got: %v GetTaxYears calls, want: 1", mock.getTaxYears)
+		}
+	})
+
+	t.Run("Test empty batch", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/batch", bytes.NewBufferString("[]"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&MockTax{})
+		handler.BatchTaxHandler(c)
+
+		want := Err{Message: "batch must not be empty"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
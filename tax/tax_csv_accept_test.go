@@ -0,0 +1,144 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCsvAccept(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	t.Run("Test answer with csv", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation"})
+		writer.Write([]string{"500000", "0", "0"})
+		writer.Write([]string{"600000", "40000", "20000"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req.Header.Set(echo.HeaderAccept, "text/csv, application/json;q=0.5")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get(echo.HeaderContentType); got != "text/csv" {
+			t.Errorf("got: %v, want: %v", got, "text/csv")
+		}
+
+		got, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Errorf("failed to read csv: %v", err)
+		}
+
+		want := [][]string{
+			{"totalIncome", "wht", "donation", "tax", "taxRefund"},
+			{"500000", "0", "0", "29000.00", "0.00"},
+			{"600000", "40000", "20000", "0.00", "2000.00"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test csv with tax levels and rejected row", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht"})
+		writer.Write([]string{"500000", "0"})
+		writer.Write([]string{"abc", "0"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?taxLevel=true", body)
+		req.Header.Set(echo.HeaderAccept, "text/csv")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		got, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Errorf("failed to read csv: %v", err)
+		}
+
+		want := [][]string{
+			{"totalIncome", "wht", "tax", "taxRefund", "0-150,000", "150,001-500,000", "500,001-1,000,000", "1,000,001-2,000,000", "2,000,001 ขึ้นไป", "error"},
+			{"500000", "0", "29000.00", "0.00", "0.00", "29000.00", "0.00", "0.00", "0.00", ""},
+			{"abc", "0", "", "", "", "", "", "", "", "invalid field totalIncome"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	accepts := []struct {
+		name   string
+		query  string
+		accept string
+		status int
+		want   string
+	}{
+		{"csv refused with q=0", "", "text/csv;q=0, application/json", http.StatusOK, "application/json"},
+		{"json preferred by q", "", "text/csv;q=0.5, application/json", http.StatusOK, "application/json"},
+		{"csv preferred over wildcard", "", "*/*;q=0.1, text/*", http.StatusOK, "text/csv"},
+		{"nothing acceptable", "", "text/html", http.StatusNotAcceptable, "application/json"},
+		{"stream with csv", "?stream=true", "text/csv", http.StatusNotAcceptable, "application/json"},
+		{"stream with any", "?stream=true", "*/*", http.StatusOK, mime_ndjson},
+	}
+	for _, v := range accepts {
+		t.Run("Test Accept "+v.name, func(t *testing.T) {
+			e := echo.New()
+
+			body := new(bytes.Buffer)
+			writer := csv.NewWriter(body)
+			writer.Write([]string{"totalIncome", "wht", "donation"})
+			writer.Write([]string{"500000", "0", "0"})
+			writer.Flush()
+
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv"+v.query, body)
+			req.Header.Set(echo.HeaderAccept, v.accept)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := New(&mock)
+			handler.UploadCSVHandler(c)
+
+			if rec.Code != v.status {
+				t.Errorf("got: %v, want: %v", rec.Code, v.status)
+			}
+			if got, _, _ := strings.Cut(rec.Header().Get(echo.HeaderContentType), ";"); got != v.want {
+				t.Errorf("got: %v, want: %v", got, v.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+//go:build unit
+
+package tax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestCsvCharset(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	upload := func(target string, body string, accept string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		if accept != "" {
+			req.Header.Set(echo.HeaderAccept, accept)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		return rec
+	}
+
+	taxes := func(t *testing.T, rec *httptest.ResponseRecorder) []Money {
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v, body: %s", rec.Code, http.StatusOK, rec.Body.Bytes())
+		}
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		var res []Money
+		for _, v := range got.Taxes {
+			res = append(res, v.Tax)
+		}
+
+		return res
+	}
+
+	t.Run("Test strip utf-8 BOM", func(t *testing.T) {
+		rec := upload("/tax/calculations/upload-csv", "\xef\xbb\xbftotalIncome,wht,donation\n500000,0,0\n", "")
+
+		want := []Money{Baht(29000)}
+		if got := taxes(t, rec); !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test semicolon and tab delimiters", func(t *testing.T) {
+		for _, body := range []string{
+			"totalIncome;wht;donation\n500000;0;0\n750000;50000;15000\n",
+			"totalIncome\twht\tdonation\n500000\t0\t0\n750000\t50000\t15000\n",
+		} {
+			rec := upload("/tax/calculations/upload-csv", body, "")
+
+			want := []Money{Baht(29000), Baht(11250)}
+			if got := taxes(t, rec); !reflect.DeepEqual(got, want) {
+				t.Errorf("got: %v, want: %v", got, want)
+			}
+		}
+	})
+
+	t.Run("Test detect windows-874", func(t *testing.T) {
+		body, _ := charmap.Windows874.NewEncoder().String("totalIncome;wht\n500000;0\nไม่มี;0\n")
+
+		for _, target := range []string{
+			"/tax/calculations/upload-csv",
+			"/tax/calculations/upload-csv?charset=TIS-620",
+		} {
+			rec := upload(target, body, "text/csv")
+
+			got, err := csv.NewReader(rec.Body).ReadAll()
+			if err != nil {
+				t.Errorf("failed to read csv: %v", err)
+			}
+
+			want := [][]string{
+				{"totalIncome", "wht", "tax", "taxRefund", "error"},
+				{"500000", "0", "29000.00", "0.00", ""},
+				{"ไม่มี", "0", "", "", "invalid field totalIncome"},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got: %v, want: %v", got, want)
+			}
+		}
+	})
+
+	t.Run("Test unsupported charset", func(t *testing.T) {
+		rec := upload("/tax/calculations/upload-csv?charset=big5", "totalIncome\n500000\n", "")
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		want := Err{Message: "unsupported charset big5"}
+		var got Err
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
+
+func TestValidUTF8(t *testing.T) {
+	thai := []byte("ภาษี")
+
+	if !validUTF8(thai[:len(thai)-1], true) {
+		t.Errorf("got: false, want: true for a rune cut off at the end of a partial read")
+	}
+	if validUTF8(thai[:len(thai)-1], false) {
+		t.Errorf("got: true, want: false for a rune cut off at the end of the body")
+	}
+	legacy, _ := charmap.Windows874.NewEncoder().Bytes(thai)
+	if validUTF8(legacy, true) {
+		t.Errorf("got: true, want: false for windows-874 bytes")
+	}
+}
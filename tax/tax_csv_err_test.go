@@ -32,11 +32,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -78,11 +78,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -124,11 +124,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -170,11 +170,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -208,7 +208,7 @@ func TestCsvErrorHander(t *testing.T) {
 		writer.Write([]string{"30ab"})
 		writer.Flush()
 
-		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -217,11 +217,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -255,7 +255,7 @@ func TestCsvErrorHander(t *testing.T) {
 		writer.Write([]string{"30", "30ab"})
 		writer.Flush()
 
-		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -264,11 +264,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -302,7 +302,7 @@ func TestCsvErrorHander(t *testing.T) {
 		writer.Write([]string{"30", "30ab"})
 		writer.Flush()
 
-		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -311,11 +311,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -349,7 +349,7 @@ func TestCsvErrorHander(t *testing.T) {
 		writer.Write([]string{"30", "30ab"})
 		writer.Flush()
 
-		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -358,11 +358,15 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
+				},
+				{
+					Type:   "K-Receipt",
+					Amount: Baht(50000),
 				},
 			},
 		}
@@ -396,7 +400,7 @@ func TestCsvErrorHander(t *testing.T) {
 		writer.Write([]string{"10", "30"})
 		writer.Flush()
 
-		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -405,11 +409,11 @@ func TestCsvErrorHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
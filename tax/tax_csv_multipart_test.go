@@ -0,0 +1,218 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func multipartCsv(field string, files map[string][][]string, order ...string) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	form := multipart.NewWriter(body)
+	for _, name := range order {
+		part, _ := form.CreateFormFile(field, name)
+		writer := csv.NewWriter(part)
+		writer.WriteAll(files[name])
+	}
+	form.Close()
+
+	return body, form.FormDataContentType()
+}
+
+func TestCsvMultipart(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	t.Run("Test taxFile upload", func(t *testing.T) {
+		e := echo.New()
+
+		body, content_type := multipartCsv("taxFile", map[string][][]string{
+			"taxes.csv": {
+				{"totalIncome", "wht", "donation"},
+				{"500000", "0", "0"},
+				{"600000", "40000", "20000"},
+			},
+		}, "taxes.csv")
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req.Header.Set(echo.HeaderContentType, content_type)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if len(got.Taxes) != 2 || got.Taxes[0].File != "taxes.csv" || got.Taxes[0].Tax != Baht(29000) || got.Taxes[1].TaxRefund != Baht(2000) {
+			t.Errorf("got: %v, want taxes.csv with tax 29000 and taxRefund 2000", got.Taxes)
+		}
+	})
+
+	t.Run("Test multiple files", func(t *testing.T) {
+		e := echo.New()
+
+		body, content_type := multipartCsv("taxFile", map[string][][]string{
+			"a.csv": {
+				{"totalIncome", "wht"},
+				{"500000", "0"},
+			},
+			"b.csv": {
+				{"donation", "totalIncome"},
+				{"abc", "600000"},
+				{"15000", "750000"},
+			},
+		}, "a.csv", "b.csv")
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req.Header.Set(echo.HeaderContentType, content_type)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if len(got.Taxes) != 2 || got.Taxes[0].File != "a.csv" || got.Taxes[1].File != "b.csv" || got.Taxes[1].Tax != Baht(61250) {
+			t.Errorf("got: %v, want a.csv and b.csv", got.Taxes)
+		}
+		wantErrors := []CsvError{{File: "b.csv", Row: 2, Column: "donation", Reason: "invalid field donation"}}
+		if !reflect.DeepEqual(got.Errors, wantErrors) {
+			t.Errorf("got: %v, want: %v", got.Errors, wantErrors)
+		}
+		if got.Summary != (CsvSummary{Accepted: 2, Rejected: 1}) {
+			t.Errorf("got: %v, want: %v", got.Summary, CsvSummary{Accepted: 2, Rejected: 1})
+		}
+	})
+
+	t.Run("Test multiple files as csv", func(t *testing.T) {
+		e := echo.New()
+
+		body, content_type := multipartCsv("taxFile", map[string][][]string{
+			"a.csv": {
+				{"totalIncome", "wht"},
+				{"500000", "0"},
+			},
+			"b.csv": {
+				{"donation", "totalIncome"},
+				{"0", "500000"},
+			},
+		}, "a.csv", "b.csv")
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req.Header.Set(echo.HeaderContentType, content_type)
+		req.Header.Set(echo.HeaderAccept, "text/csv")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		got, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Errorf("failed to read csv: %v", err)
+		}
+
+		want := [][]string{
+			{"file", "totalIncome", "wht", "donation", "tax", "taxRefund"},
+			{"a.csv", "500000", "0", "", "29000.00", "0.00"},
+			{"b.csv", "500000", "", "0", "29000.00", "0.00"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test missing taxFile", func(t *testing.T) {
+		e := echo.New()
+
+		body, content_type := multipartCsv("file", map[string][][]string{
+			"taxes.csv": {
+				{"totalIncome"},
+				{"500000"},
+			},
+		}, "taxes.csv")
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req.Header.Set(echo.HeaderContentType, content_type)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		want := Err{Message: "taxFile is required"}
+		var got Err
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test file too large", func(t *testing.T) {
+		e := echo.New()
+
+		rows := [][]string{{"totalIncome"}}
+		for len(rows) < max_csv_file_size/8 {
+			rows = append(rows, []string{"5000000"})
+		}
+		body, content_type := multipartCsv("taxFile", map[string][][]string{"big.csv": rows}, "big.csv")
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		req.Header.Set(echo.HeaderContentType, content_type)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusRequestEntityTooLarge)
+		}
+
+		want := Err{Message: "taxFile big.csv must not be larger than 10 MB"}
+		var got Err
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// yearDeductionTax configures deductions per tax year, falling back to
+// MockTax for every year it does not list.
+type yearDeductionTax struct {
+	MockTax
+	byYear map[int][]DbDeduction
+}
+
+func (m yearDeductionTax) GetTaxDeducations(tax_year int) ([]DbDeduction, error) {
+	if deductions, ok := m.byYear[tax_year]; ok {
+		return deductions, nil
+	}
+
+	return m.MockTax.GetTaxDeducations(tax_year)
+}
+
+func TestCsvRowError(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+		taxYears: []int{2567},
+	}
+
+	t.Run("Test bad rows are reported and good rows kept", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation", "taxYear"})
+		writer.Write([]string{"500000", "0", "0", "2567"})
+		writer.Write([]string{"600000", "abc", "20000", "2567"})
+		writer.Write([]string{"600000", "0", "0", "2500"})
+		writer.Write([]string{"600000", "0"})
+		writer.Write([]string{"750000", "50000", "15000", "2567"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if len(got.Taxes) != 2 || got.Taxes[0].Tax != Baht(29000) || got.Taxes[1].Tax != Baht(11250) {
+			t.Errorf("got: %v, want taxes: 29000, 11250", got.Taxes)
+		} else if got.Taxes[0].Row != 2 || got.Taxes[1].Row != 6 {
+			t.Errorf("got rows: %v, %v, want: 2, 6", got.Taxes[0].Row, got.Taxes[1].Row)
+		}
+
+		wantErrors := []CsvError{
+			{Row: 3, Column: "wht", Reason: "invalid field wht"},
+			{Row: 4, Column: "taxYear", Reason: "tax year not found: 2500"},
+			{Row: 5, Reason: "wrong number of fields"},
+		}
+		if !reflect.DeepEqual(got.Errors, wantErrors) {
+			t.Errorf("got: %v, want: %v", got.Errors, wantErrors)
+		}
+
+		wantSummary := CsvSummary{Accepted: 2, Rejected: 3}
+		if got.Summary != wantSummary {
+			t.Errorf("got: %v, want: %v", got.Summary, wantSummary)
+		}
+	})
+
+	t.Run("Test rows checked like json requests", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation", "taxYear"})
+		writer.Write([]string{"-500000", "900000", "0", "2567"})
+		writer.Write([]string{"500000", "900000", "0", "2567"})
+		writer.Write([]string{"500000", "0", "-10000", "2567"})
+		writer.Write([]string{"500000", "0", "10000", "2566"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		year_mock := mock
+		year_mock.taxYears = []int{2566, 2567}
+		handler := New(yearDeductionTax{
+			MockTax: year_mock,
+			byYear: map[int][]DbDeduction{
+				2566: {{Type: "Personal", Amount: Baht(60000)}, {Type: "K-Receipt", Amount: Baht(50000)}},
+			},
+		})
+		handler.UploadCSVHandler(c)
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		want := ResAllCsv{
+			Taxes: []ResCsvTax{},
+			Errors: []CsvError{
+				{Row: 2, Column: "totalIncome", Reason: "totalIncome must be greater than 0"},
+				{Row: 3, Column: "wht", Reason: "Wht must be less than totalIncome"},
+				{Row: 4, Column: "donation", Reason: "Amount must be greater than 0"},
+				{Row: 5, Column: "donation", Reason: "Not found allowanceType"},
+			},
+			Summary: CsvSummary{Rejected: 4},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test unknown tax year loaded once", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "taxYear"})
+		for range 4 {
+			writer.Write([]string{"500000", "2599"})
+		}
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		count_mock := countingTax{MockTax: mock}
+		handler := New(&count_mock)
+		handler.UploadCSVHandler(c)
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		wantSummary := CsvSummary{Rejected: 4}
+		if got.Summary != wantSummary {
+			t.Errorf("got: %v, want: %v", got.Summary, wantSummary)
+		}
+		if count_mock.getTax != 1 {
+			t.Errorf("got: %v GetTax calls, want: 1", count_mock.getTax)
+		}
+	})
+
+	t.Run("Test every row rejected", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "donation", "taxYear"})
+		writer.Write([]string{"500000", "abc", "2567"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		want := ResAllCsv{
+			Taxes:   []ResCsvTax{},
+			Errors:  []CsvError{{Row: 2, Column: "donation", Reason: "invalid field donation"}},
+			Summary: CsvSummary{Rejected: 1},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test strict fails the whole file", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "taxYear"})
+		writer.Write([]string{"500000", "0", "2567"})
+		writer.Write([]string{"600000", "abc", "2567"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		want := Err{Message: "invalid field wht"}
+		var got Err
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+//go:build unit
+
+package tax
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCsvStream(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	t.Run("Test stream writes one line per row", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation"})
+		writer.Write([]string{"500000", "0", "0"})
+		writer.Write([]string{"600000", "40000", "20000"})
+		writer.Write([]string{"750000", "50000", "15000"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?stream=true", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get(echo.HeaderContentType); got != "application/x-ndjson" {
+			t.Errorf("got: %v, want: %v", got, "application/x-ndjson")
+		}
+
+		var lines [][]byte
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			lines = append(lines, bytes.Clone(scanner.Bytes()))
+		}
+
+		want := []Money{Baht(29000), 0, Baht(11250)}
+		if len(lines) != len(want)+1 {
+			t.Fatalf("got: %v lines, want: %v", len(lines), len(want)+1)
+		}
+		var got []ResCsvTax
+		for _, line := range lines[:len(want)] {
+			var res ResCsvTax
+			if err := json.Unmarshal(line, &res); err != nil {
+				t.Errorf("failed to unmarshal json: %v", err)
+			}
+			got = append(got, res)
+		}
+		for i, v := range want {
+			if got[i].Tax != v || got[i].Row != i+2 {
+				t.Errorf("got: %v, want tax: %v on row %v", got[i], v, i+2)
+			}
+		}
+		if got[1].TaxRefund != Baht(2000) {
+			t.Errorf("got: %v, want taxRefund: 2000", got[1])
+		}
+
+		var summary CsvSummary
+		if err := json.Unmarshal(lines[len(want)], &summary); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if summary != (CsvSummary{Accepted: 3}) {
+			t.Errorf("got: %v, want: %v", summary, CsvSummary{Accepted: 3})
+		}
+	})
+
+	t.Run("Test stream ends with error line", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation"})
+		writer.Write([]string{"500000", "0", "0"})
+		writer.Write([]string{"600000", "abc", "20000"})
+		writer.Write([]string{"750000", "50000", "15000"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?stream=true&strict=true", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		lines := bytes.Split(bytes.TrimSpace(rec.Body.Bytes()), []byte("\n"))
+		if len(lines) != 2 {
+			t.Fatalf("got: %s, want 2 lines", rec.Body.Bytes())
+		}
+
+		want := Err{Message: "invalid field wht"}
+		var got Err
+		if err := json.Unmarshal(lines[1], &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test strict stream ends with summary", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation"})
+		writer.Write([]string{"500000", "0", "0"})
+		writer.Write([]string{"750000", "50000", "15000"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?stream=true&strict=true", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		lines := bytes.Split(bytes.TrimSpace(rec.Body.Bytes()), []byte("\n"))
+		if len(lines) != 3 {
+			t.Fatalf("got: %s, want 3 lines", rec.Body.Bytes())
+		}
+
+		var summary CsvSummary
+		if err := json.Unmarshal(lines[2], &summary); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if summary != (CsvSummary{Accepted: 2}) {
+			t.Errorf("got: %v, want: %v", summary, CsvSummary{Accepted: 2})
+		}
+	})
+
+	t.Run("Test stream bad first row", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht"})
+		writer.Write([]string{"abc", "0"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?stream=true&strict=true", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Test stream keeps going after a bad row", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation"})
+		writer.Write([]string{"500000", "0", "0"})
+		writer.Write([]string{"600000", "abc", "20000"})
+		writer.Write([]string{"750000", "50000", "15000"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?stream=true", body)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		lines := bytes.Split(bytes.TrimSpace(rec.Body.Bytes()), []byte("\n"))
+		if len(lines) != 4 {
+			t.Fatalf("got: %s, want 4 lines", rec.Body.Bytes())
+		}
+
+		want := CsvError{Row: 3, Column: "wht", Reason: "invalid field wht"}
+		var got CsvError
+		if err := json.Unmarshal(lines[1], &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+
+		var summary CsvSummary
+		if err := json.Unmarshal(lines[3], &summary); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+		if summary != (CsvSummary{Accepted: 2, Rejected: 1}) {
+			t.Errorf("got: %v, want: %v", summary, CsvSummary{Accepted: 2, Rejected: 1})
+		}
+	})
+}
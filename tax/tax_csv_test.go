@@ -35,11 +35,11 @@ func TestCsvHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -50,18 +50,52 @@ func TestCsvHander(t *testing.T) {
 		want := ResAllCsv{
 			Taxes: []ResCsvTax{
 				{
-					TotalIncome: 500000.0,
-					Tax:         29000.0,
+					Row:         2,
+					TotalIncome: Baht(500000),
+					Tax:         Baht(29000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(440000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: 0, Applied: 0},
+						},
+						MarginalLevel: "150,001-500,000",
+						MarginalRate:  10,
+						EffectiveRate: 5.8,
+					},
 				},
 				{
-					TotalIncome: 600000.0,
-					TaxRefund:   2000.0,
+					Row:         3,
+					TotalIncome: Baht(600000),
+					TaxRefund:   Baht(2000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(520000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: Baht(20000), Applied: Baht(20000)},
+						},
+						MarginalLevel: "500,001-1,000,000",
+						MarginalRate:  15,
+						EffectiveRate: 6.33,
+					},
 				},
 				{
-					TotalIncome: 750000.0,
-					Tax:         11250.0,
+					Row:         4,
+					TotalIncome: Baht(750000),
+					Tax:         Baht(11250),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(675000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: Baht(15000), Applied: Baht(15000)},
+						},
+						MarginalLevel: "500,001-1,000,000",
+						MarginalRate:  15,
+						EffectiveRate: 8.17,
+					},
 				},
 			},
+			Summary: CsvSummary{Accepted: 3},
 		}
 		gotJson := rec.Body.Bytes()
 
@@ -99,11 +133,11 @@ func TestCsvHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -114,18 +148,52 @@ func TestCsvHander(t *testing.T) {
 		want := ResAllCsv{
 			Taxes: []ResCsvTax{
 				{
-					TotalIncome: 1000000.0,
-					Tax:         101000.0,
+					Row:         2,
+					TotalIncome: Baht(1000000),
+					Tax:         Baht(101000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(940000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: 0, Applied: 0},
+						},
+						MarginalLevel: "500,001-1,000,000",
+						MarginalRate:  15,
+						EffectiveRate: 10.1,
+					},
 				},
 				{
-					TotalIncome: 500000.0,
-					Tax:         4000.0,
+					Row:         3,
+					TotalIncome: Baht(500000),
+					Tax:         Baht(4000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(440000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: 0, Applied: 0},
+						},
+						MarginalLevel: "150,001-500,000",
+						MarginalRate:  10,
+						EffectiveRate: 5.8,
+					},
 				},
 				{
-					TotalIncome: 500000.0,
-					Tax:         19000.0,
+					Row:         4,
+					TotalIncome: Baht(500000),
+					Tax:         Baht(19000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(340000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: Baht(200000), Applied: Baht(100000)},
+						},
+						MarginalLevel: "150,001-500,000",
+						MarginalRate:  10,
+						EffectiveRate: 3.8,
+					},
 				},
 			},
+			Summary: CsvSummary{Accepted: 3},
 		}
 		gotJson := rec.Body.Bytes()
 
@@ -163,11 +231,11 @@ func TestCsvHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -178,18 +246,49 @@ func TestCsvHander(t *testing.T) {
 		want := ResAllCsv{
 			Taxes: []ResCsvTax{
 				{
-					TotalIncome: 1000000.0,
-					Tax:         101000.0,
+					Row:         2,
+					TotalIncome: Baht(1000000),
+					Tax:         Baht(101000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(940000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+						},
+						MarginalLevel: "500,001-1,000,000",
+						MarginalRate:  15,
+						EffectiveRate: 10.1,
+					},
 				},
 				{
-					TotalIncome: 2000000.0,
-					Tax:         298000.0,
+					Row:         3,
+					TotalIncome: Baht(2000000),
+					Tax:         Baht(298000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(1940000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+						},
+						MarginalLevel: "1,000,001-2,000,000",
+						MarginalRate:  20,
+						EffectiveRate: 14.9,
+					},
 				},
 				{
-					TotalIncome: 3000000.0,
-					Tax:         639000.0,
+					Row:         4,
+					TotalIncome: Baht(3000000),
+					Tax:         Baht(639000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(2940000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+						},
+						MarginalLevel: "2,000,001 ขึ้นไป",
+						MarginalRate:  35,
+						EffectiveRate: 21.3,
+					},
 				},
 			},
+			Summary: CsvSummary{Accepted: 3},
 		}
 		gotJson := rec.Body.Bytes()
 
@@ -227,11 +326,11 @@ func TestCsvHander(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -242,18 +341,52 @@ func TestCsvHander(t *testing.T) {
 		want := ResAllCsv{
 			Taxes: []ResCsvTax{
 				{
-					TotalIncome: 1000000.0,
-					Tax:         101000.0,
+					Row:         2,
+					TotalIncome: Baht(1000000),
+					Tax:         Baht(101000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(940000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: 0, Applied: 0},
+						},
+						MarginalLevel: "500,001-1,000,000",
+						MarginalRate:  15,
+						EffectiveRate: 10.1,
+					},
 				},
 				{
-					TotalIncome: 500000.0,
-					Tax:         4000.0,
+					Row:         3,
+					TotalIncome: Baht(500000),
+					Tax:         Baht(4000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(440000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: 0, Applied: 0},
+						},
+						MarginalLevel: "150,001-500,000",
+						MarginalRate:  10,
+						EffectiveRate: 5.8,
+					},
 				},
 				{
-					TotalIncome: 500000.0,
-					Tax:         19000.0,
+					Row:         4,
+					TotalIncome: Baht(500000),
+					Tax:         Baht(19000),
+					TaxDetail: TaxDetail{
+						TaxableIncome: Baht(340000),
+						Deductions: []AppliedDeduction{
+							{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+							{Type: "donation", Requested: Baht(200000), Applied: Baht(100000)},
+						},
+						MarginalLevel: "150,001-500,000",
+						MarginalRate:  10,
+						EffectiveRate: 3.8,
+					},
 				},
 			},
+			Summary: CsvSummary{Accepted: 3},
 		}
 		gotJson := rec.Body.Bytes()
 
@@ -270,4 +403,58 @@ func TestCsvHander(t *testing.T) {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
 	})
+
+	t.Run("Test taxLevel option", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "donation"})
+		writer.Write([]string{"500000", "0", "0"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?taxLevel=true", body)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
+				},
+			},
+		}
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResAllCsv
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		want := []TaxLevel{
+			{Level: "0-150,000", Tax: 0},
+			{Level: "150,001-500,000", Tax: Baht(29000)},
+			{Level: "500,001-1,000,000", Tax: 0},
+			{Level: "1,000,001-2,000,000", Tax: 0},
+			{Level: "2,000,001 ขึ้นไป", Tax: 0},
+		}
+		if len(got.Taxes) != 1 || !reflect.DeepEqual(got.Taxes[0].TaxLevel, want) || got.Taxes[0].TaxableIncome != Baht(440000) {
+			t.Errorf("got: %v, want: %v", got.Taxes, want)
+		}
+
+		// same key as the TaxLevel of tax/calculations
+		if !bytes.Contains(gotJson, []byte(`"TaxLevel":[`)) {
+			t.Errorf("got: %s, want key TaxLevel", gotJson)
+		}
+	})
 }
@@ -17,7 +17,7 @@ func TestDeductionKRecepiptHandler(t *testing.T) {
 	t.Run("Test set amount k-receipt over 100,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqAmount{
-			Amount: 100001.0,
+			Amount: Baht(100001),
 		}
 		reqBody, _ := json.Marshal(MockReq)
 		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/k-receipt", bytes.NewBuffer(reqBody))
@@ -29,8 +29,8 @@ func TestDeductionKRecepiptHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:           "K-Receipt",
-					Maximum_amount: 100000.0,
-					Minimum_amount: 0.0,
+					Maximum_amount: Baht(100000),
+					Minimum_amount: 0,
 				},
 			},
 		}
@@ -70,8 +70,8 @@ func TestDeductionKRecepiptHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:           "K-Receipt",
-					Maximum_amount: 100000.0,
-					Minimum_amount: 0.0,
+					Maximum_amount: Baht(100000),
+					Minimum_amount: 0,
 				},
 			},
 		}
@@ -99,7 +99,7 @@ func TestDeductionKRecepiptHandler(t *testing.T) {
 	t.Run("Test set amount k-receipt 70,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqAmount{
-			Amount: 70000.0,
+			Amount: Baht(70000),
 		}
 		reqBody, _ := json.Marshal(MockReq)
 		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/k-receipt", bytes.NewBuffer(reqBody))
@@ -111,8 +111,8 @@ func TestDeductionKRecepiptHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:           "K-Receipt",
-					Maximum_amount: 100000.0,
-					Minimum_amount: 0.0,
+					Maximum_amount: Baht(100000),
+					Minimum_amount: 0,
 				},
 			},
 		}
@@ -120,7 +120,7 @@ func TestDeductionKRecepiptHandler(t *testing.T) {
 		handler := New(&mock)
 		handler.TaxDeducateKreceiptHandler(c)
 
-		want := ResKReceiptDeduction{KReceipt: 70000.0}
+		want := ResKReceiptDeduction{KReceipt: Baht(70000)}
 		gotJson := rec.Body.Bytes()
 
 		var got ResKReceiptDeduction
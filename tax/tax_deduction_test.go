@@ -17,7 +17,7 @@ func TestDeductionPersonalHandler(t *testing.T) {
 	t.Run("Test set amount personal over 100,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqAmount{
-			Amount: 100001.0,
+			Amount: Baht(100001),
 		}
 		reqBody, _ := json.Marshal(MockReq)
 		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/personal", bytes.NewBuffer(reqBody))
@@ -29,8 +29,8 @@ func TestDeductionPersonalHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:           "Personal",
-					Maximum_amount: 100000.0,
-					Minimum_amount: 10000.0,
+					Maximum_amount: Baht(100000),
+					Minimum_amount: Baht(10000),
 				},
 			},
 		}
@@ -58,7 +58,7 @@ func TestDeductionPersonalHandler(t *testing.T) {
 	t.Run("Test set amount personal less than 10,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqAmount{
-			Amount: 9999.0,
+			Amount: Baht(9999),
 		}
 		reqBody, _ := json.Marshal(MockReq)
 		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/personal", bytes.NewBuffer(reqBody))
@@ -70,8 +70,8 @@ func TestDeductionPersonalHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:           "Personal",
-					Maximum_amount: 100000.0,
-					Minimum_amount: 10000.0,
+					Maximum_amount: Baht(100000),
+					Minimum_amount: Baht(10000),
 				},
 			},
 		}
@@ -99,7 +99,7 @@ func TestDeductionPersonalHandler(t *testing.T) {
 	t.Run("Test set amount personal 70,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqAmount{
-			Amount: 70000.0,
+			Amount: Baht(70000),
 		}
 		reqBody, _ := json.Marshal(MockReq)
 		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/personal", bytes.NewBuffer(reqBody))
@@ -111,8 +111,8 @@ func TestDeductionPersonalHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:           "Personal",
-					Maximum_amount: 100000.0,
-					Minimum_amount: 10000.0,
+					Maximum_amount: Baht(100000),
+					Minimum_amount: Baht(10000),
 				},
 			},
 		}
@@ -120,7 +120,7 @@ func TestDeductionPersonalHandler(t *testing.T) {
 		handler := New(&mock)
 		handler.TaxDeducateHandler(c)
 
-		want := ResPersonalDeduction{PersonalDeduction: 70000.0}
+		want := ResPersonalDeduction{PersonalDeduction: Baht(70000)}
 		gotJson := rec.Body.Bytes()
 
 		var got ResPersonalDeduction
@@ -0,0 +1,130 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestDonationPercentCap(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Life-Insurance",
+				Amount: Baht(100000),
+			},
+			{
+				Type:        "Education-Donation",
+				Cap_percent: 10,
+				Multiplier:  2,
+				Apply_order: 1,
+			},
+			{
+				Type:        "Donation",
+				Amount:      Baht(100000),
+				Cap_percent: 10,
+				Apply_order: 2,
+			},
+		},
+	}
+
+	t.Run("Test donation capped at 10% after other deductions", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(1000000),
+			Allowances: []Allowance{
+				{
+					AllowanceType: "donation",
+					Amount:        Baht(100000),
+				},
+				{
+					AllowanceType: "education-donation",
+					Amount:        Baht(30000),
+				},
+				{
+					AllowanceType: "life-insurance",
+					Amount:        Baht(40000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// 1,000,000 - 60,000 - 40,000 = 900,000
+		// education donation 30,000 x 2 = 60,000, cap 90,000 -> 840,000
+		// donation 100,000, cap 84,000 -> 756,000
+		want := []AppliedDeduction{
+			{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+			{Type: "life-insurance", Requested: Baht(40000), Applied: Baht(40000)},
+			{Type: "education-donation", Requested: Baht(30000), Applied: Baht(60000)},
+			{Type: "donation", Requested: Baht(100000), Applied: Baht(84000)},
+		}
+		if !reflect.DeepEqual(got.Deductions, want) {
+			t.Errorf("got: %v, want: %v", got.Deductions, want)
+		}
+
+		if got.TaxableIncome != Baht(756000) || got.Tax != Baht(73400) {
+			t.Errorf("got: %v, want taxableIncome: 756000, tax: 73400", got)
+		}
+	})
+
+	t.Run("Test donation flat cap lower than 10%", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(3000000),
+			Allowances: []Allowance{
+				{
+					AllowanceType: "donation",
+					Amount:        Baht(500000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if got.TaxableIncome != Baht(2840000) {
+			t.Errorf("got: %v, want: %v", got.TaxableIncome, Baht(2840000))
+		}
+	})
+}
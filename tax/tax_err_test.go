@@ -18,11 +18,11 @@ func TestTaxErrorHandler(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
 			TotalIncome: -1.0,
-			Wht:         0.0,
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -54,15 +54,51 @@ func TestTaxErrorHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("Test incomes that add up past the limit", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{IncomeType: "40(1)", Amount: Baht(60_000_000_000_000_000)},
+				{IncomeType: "40(2)", Amount: Baht(60_000_000_000_000_000)},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{}
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "totalIncome must not be more than 1,000,000,000,000"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
 	t.Run("Test Wht less than 0", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 1.0,
+			TotalIncome: Baht(1),
 			Wht:         -1.0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -97,12 +133,12 @@ func TestTaxErrorHandler(t *testing.T) {
 	t.Run("Test Wht great than totalIncome", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 1.0,
-			Wht:         2.0,
+			TotalIncome: Baht(1),
+			Wht:         Baht(2),
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -137,12 +173,12 @@ func TestTaxErrorHandler(t *testing.T) {
 	t.Run("Test AllowanceType no have", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 1.0,
-			Wht:         0.0,
+			TotalIncome: Baht(1),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "test",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -152,7 +188,18 @@ func TestTaxErrorHandler(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		mock := MockTax{}
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
+				},
+				{
+					Type:   "K-Receipt",
+					Amount: Baht(50000),
+				},
+			},
+		}
 
 		handler := New(&mock)
 		handler.TaxHandler(c)
@@ -177,20 +224,20 @@ func TestTaxErrorHandler(t *testing.T) {
 	t.Run("Test Allowances more than 2", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 1.0,
-			Wht:         0.0,
+			TotalIncome: Baht(1),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 				{
 					AllowanceType: "k-receipt",
-					Amount:        0.0,
+					Amount:        0,
 				},
 				{
 					AllowanceType: "test",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -200,7 +247,18 @@ func TestTaxErrorHandler(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		mock := MockTax{}
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
+				},
+				{
+					Type:   "K-Receipt",
+					Amount: Baht(50000),
+				},
+			},
+		}
 
 		handler := New(&mock)
 		handler.TaxHandler(c)
@@ -225,16 +283,16 @@ func TestTaxErrorHandler(t *testing.T) {
 	t.Run("Test duplication allowance type", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 1.0,
-			Wht:         0.0,
+			TotalIncome: Baht(1),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -244,7 +302,18 @@ func TestTaxErrorHandler(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		mock := MockTax{}
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
+				},
+				{
+					Type:   "K-Receipt",
+					Amount: Baht(50000),
+				},
+			},
+		}
 
 		handler := New(&mock)
 		handler.TaxHandler(c)
@@ -269,8 +338,8 @@ func TestTaxErrorHandler(t *testing.T) {
 	t.Run("Test Allowance amount less than 0", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 1.0,
-			Wht:         0.0,
+			TotalIncome: Baht(1),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
@@ -284,7 +353,18 @@ func TestTaxErrorHandler(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		mock := MockTax{}
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
+				},
+				{
+					Type:   "K-Receipt",
+					Amount: Baht(50000),
+				},
+			},
+		}
 
 		handler := New(&mock)
 		handler.TaxHandler(c)
@@ -0,0 +1,96 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestExplainTrace(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+			{
+				Type:   "K-Receipt",
+				Amount: Baht(50000),
+			},
+		},
+	}
+	MockReq := ReqTax{
+		TotalIncome: Baht(500000),
+		Wht:         Baht(25000),
+		Allowances: []Allowance{
+			{
+				AllowanceType: "donation",
+				Amount:        Baht(200000),
+			},
+		},
+	}
+
+	t.Run("Test explain=true returns ordered trace", func(t *testing.T) {
+		e := echo.New()
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations?explain=true", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		want := []TraceStep{
+			{Step: "grossIncome", Amount: Baht(500000), Balance: Baht(500000)},
+			{Step: "deduction", Name: "personal", Requested: Baht(60000), Limit: Baht(60000), LimitSource: "tax_deductions.amount", Amount: Baht(60000), Balance: Baht(440000)},
+			{Step: "deduction", Name: "donation", Requested: Baht(200000), Limit: Baht(100000), LimitSource: "tax_deductions.amount", Amount: Baht(100000), Balance: Baht(340000)},
+			{Step: "taxableIncome", Amount: Baht(340000), Balance: Baht(340000)},
+			{Step: "bracket", Name: "0-150,000", Income: Baht(150000), Amount: 0, Balance: 0},
+			{Step: "bracket", Name: "150,001-500,000", Rate: 10, Income: Baht(190000), Amount: Baht(19000), Balance: Baht(19000)},
+			{Step: "wht", Amount: Baht(25000), Balance: Baht(-6000)},
+			{Step: "taxRefund", Amount: Baht(6000), Balance: Baht(6000)},
+		}
+		if !reflect.DeepEqual(got.Trace, want) {
+			t.Errorf("got: %v, want: %v", got.Trace, want)
+		}
+	})
+
+	t.Run("Test trace is omitted without explain", func(t *testing.T) {
+		e := echo.New()
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		if bytes.Contains(rec.Body.Bytes(), []byte(`"trace"`)) {
+			t.Errorf("got: %s, want no trace", rec.Body.Bytes())
+		}
+	})
+}
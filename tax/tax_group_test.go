@@ -0,0 +1,141 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestDeductionGroupCap(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:       "Life-Insurance",
+				Amount:     Baht(100000),
+				Group_name: "Insurance",
+			},
+			{
+				Type:       "Health-Insurance",
+				Amount:     Baht(25000),
+				Group_name: "Insurance",
+			},
+			{
+				Type:       "RMF",
+				Amount:     Baht(500000),
+				Group_name: "Retirement",
+			},
+			{
+				Type:       "SSF",
+				Amount:     Baht(200000),
+				Group_name: "Retirement",
+			},
+			{
+				Type:       "Provident-Fund",
+				Amount:     Baht(500000),
+				Group_name: "Retirement",
+			},
+			{
+				Type:   "K-Receipt",
+				Amount: Baht(50000),
+			},
+		},
+		dbDeductionGroup: []DbDeductionGroup{
+			{
+				Group_name: "Insurance",
+				Amount:     Baht(100000),
+			},
+			{
+				Group_name: "Retirement",
+				Amount:     Baht(500000),
+			},
+		},
+	}
+
+	t.Run("Test trim grouped allowances to combined cap", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(3000000),
+			Allowances: []Allowance{
+				{
+					AllowanceType: "life-insurance",
+					Amount:        Baht(90000),
+				},
+				{
+					AllowanceType: "health-insurance",
+					Amount:        Baht(25000),
+				},
+				{
+					AllowanceType: "rmf",
+					Amount:        Baht(300000),
+				},
+				{
+					AllowanceType: "ssf",
+					Amount:        Baht(200000),
+				},
+				{
+					AllowanceType: "provident-fund",
+					Amount:        Baht(100000),
+				},
+				{
+					AllowanceType: "k-receipt",
+					Amount:        Baht(50000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		wantDeductions := []AppliedDeduction{
+			{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+			{Type: "life-insurance", Group: "Insurance", Requested: Baht(90000), Applied: Baht(90000)},
+			{Type: "health-insurance", Group: "Insurance", Requested: Baht(25000), Applied: Baht(10000)},
+			{Type: "rmf", Group: "Retirement", Requested: Baht(300000), Applied: Baht(300000)},
+			{Type: "ssf", Group: "Retirement", Requested: Baht(200000), Applied: Baht(200000)},
+			{Type: "provident-fund", Group: "Retirement", Requested: Baht(100000), Applied: 0},
+			{Type: "k-receipt", Requested: Baht(50000), Applied: Baht(50000)},
+		}
+		if !reflect.DeepEqual(got.Deductions, wantDeductions) {
+			t.Errorf("got: %v, want: %v", got.Deductions, wantDeductions)
+		}
+
+		wantGroups := []AppliedGroup{
+			{Group: "Insurance", Limit: Baht(100000), Applied: Baht(100000)},
+			{Group: "Retirement", Limit: Baht(500000), Applied: Baht(500000)},
+		}
+		if !reflect.DeepEqual(got.DeductionGroups, wantGroups) {
+			t.Errorf("got: %v, want: %v", got.DeductionGroups, wantGroups)
+		}
+
+		if got.TaxableIncome != Baht(2290000) {
+			t.Errorf("got: %v, want: %v", got.TaxableIncome, Baht(2290000))
+		}
+	})
+}
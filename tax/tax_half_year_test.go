@@ -0,0 +1,177 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestHalfYearTax(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	t.Run("Test half-year 40(5) income 600,000 halves personal deduction", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(5)",
+					Amount:     Baht(600000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/half-year", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.HalfYearTaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// 600,000 - expense 180,000 - personal 30,000 = 390,000
+		if got.TaxableIncome != Baht(390000) || got.Tax != Baht(24000) {
+			t.Errorf("got: %v, want taxableIncome: 390000, tax: 24000", got)
+		}
+
+		wantMethod := &TaxMethod{Method: "progressive", ProgressiveTax: Baht(24000), MinimumTax: Baht(3000)}
+		if !reflect.DeepEqual(got.TaxMethod, wantMethod) {
+			t.Errorf("got: %v, want: %v", got.TaxMethod, wantMethod)
+		}
+	})
+
+	t.Run("Test half-year rejects 40(1) income", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(1)",
+					Amount:     Baht(600000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/half-year", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.HalfYearTaxHandler(c)
+
+		want := Err{Message: "half-year return only accepts 40(5) to 40(8) incomes"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test annual return credits pnd94", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(5)",
+					Amount:     Baht(1200000),
+				},
+			},
+			Pnd94: Baht(24000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		// 1,200,000 - expense 360,000 - personal 60,000 = 780,000 -> 77,000
+		if got.Tax != Baht(53000) || got.TaxRefund != 0 {
+			t.Errorf("got: %v, want tax: 53000, taxRefund: 0", got)
+		}
+
+		want := &TaxCredit{Pnd94: Baht(24000), Total: Baht(24000)}
+		if !reflect.DeepEqual(got.TaxCredit, want) {
+			t.Errorf("got: %v, want: %v", got.TaxCredit, want)
+		}
+	})
+
+	t.Run("Test annual return refunds wht and pnd94 over the tax", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(5)",
+					Amount:     Baht(1200000),
+				},
+			},
+			Wht:   Baht(60000),
+			Pnd94: Baht(24000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if got.Tax != 0 || got.TaxRefund != Baht(7000) {
+			t.Errorf("got: %v, want tax: 0, taxRefund: 7000", got)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestHouseholdHandler(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Spouse",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	t.Run("Test spouse income 50,000 recommends joint filing", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqHousehold{
+			Taxpayer: ReqTax{TotalIncome: Baht(1000000)},
+			Spouse:   ReqTax{TotalIncome: Baht(50000)},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/household", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.HouseholdHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResHousehold
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// separate: 940,000 -> 101,000 and 0
+		// joint: 1,050,000 - 60,000 - spouse 60,000 = 930,000 -> 99,500
+		if got.SeparateTax != Baht(101000) || got.JointTax != Baht(99500) {
+			t.Errorf("got: %v, want separateTax: 101000, jointTax: 99500", got)
+		}
+		if got.Recommendation != "joint" || got.TaxSaved != Baht(1500) {
+			t.Errorf("got: %v, want recommendation: joint, taxSaved: 1500", got)
+		}
+
+		want := []AppliedDeduction{
+			{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+			{Type: "spouse", Requested: Baht(60000), Applied: Baht(60000)},
+		}
+		if !reflect.DeepEqual(got.Joint.Deductions, want) {
+			t.Errorf("got: %v, want: %v", got.Joint.Deductions, want)
+		}
+	})
+
+	t.Run("Test spouse income 100,000 recommends separate filing", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqHousehold{
+			Taxpayer: ReqTax{TotalIncome: Baht(1000000), Wht: Baht(50000)},
+			Spouse: ReqTax{
+				TotalIncome: Baht(100000),
+				Allowances: []Allowance{
+					{
+						AllowanceType: "donation",
+						Amount:        Baht(10000),
+					},
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/household", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.HouseholdHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResHousehold
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// separate: 101,000 - wht 50,000 and 0
+		// joint: 1,100,000 - 60,000 - 60,000 - 10,000 = 970,000 -> 105,500 - wht 50,000
+		if got.SeparateTax != Baht(51000) || got.JointTax != Baht(55500) {
+			t.Errorf("got: %v, want separateTax: 51000, jointTax: 55500", got)
+		}
+		if got.Recommendation != "separate" || got.TaxSaved != Baht(4500) {
+			t.Errorf("got: %v, want recommendation: separate, taxSaved: 4500", got)
+		}
+	})
+
+	t.Run("Test pnd94 credited in joint return", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqHousehold{
+			Taxpayer: ReqTax{TotalIncome: Baht(1000000), Pnd94: Baht(50000)},
+			Spouse:   ReqTax{TotalIncome: Baht(50000)},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/household", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.HouseholdHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResHousehold
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// separate: 101,000 - pnd94 50,000 and 0
+		// joint: 99,500 - pnd94 50,000
+		if got.SeparateTax != Baht(51000) || got.JointTax != Baht(49500) {
+			t.Errorf("got: %v, want separateTax: 51000, jointTax: 49500", got)
+		}
+		if got.Recommendation != "joint" || got.TaxSaved != Baht(1500) {
+			t.Errorf("got: %v, want recommendation: joint, taxSaved: 1500", got)
+		}
+
+		want := &TaxCredit{Pnd94: Baht(50000), Total: Baht(50000)}
+		if !reflect.DeepEqual(got.Joint.TaxCredit, want) {
+			t.Errorf("got: %v, want: %v", got.Joint.TaxCredit, want)
+		}
+	})
+
+	t.Run("Test spouse allowance in separate return", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqHousehold{
+			Taxpayer: ReqTax{
+				TotalIncome: Baht(1000000),
+				Allowances: []Allowance{
+					{
+						AllowanceType: "spouse",
+						Amount:        Baht(60000),
+					},
+				},
+			},
+			Spouse: ReqTax{TotalIncome: Baht(50000)},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/household", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.HouseholdHandler(c)
+
+		want := Err{Message: "spouse allowance is applied by joint filing"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,340 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIncomeTypeHandler(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+		},
+	}
+
+	t.Run("Test 40(1) and 40(2) share expense cap 100,000", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(1)",
+					Amount:     Baht(600000),
+				},
+				{
+					IncomeType: "40(2)",
+					Amount:     Baht(100000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		wantExpenses := []AppliedExpense{
+			{
+				ExpenseGroup: "40(1)-40(2)",
+				Income:       Baht(700000),
+				Expense:      Baht(100000),
+			},
+		}
+		if !reflect.DeepEqual(got.Expenses, wantExpenses) {
+			t.Errorf("got: %v, want: %v", got.Expenses, wantExpenses)
+		}
+
+		if got.TaxableIncome != Baht(540000) || got.Tax != Baht(41000) {
+			t.Errorf("got: %v, want taxableIncome: 540000, tax: 41000", got)
+		}
+	})
+
+	t.Run("Test 40(8) expense 60%", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(1000000),
+			Incomes: []Income{
+				{
+					IncomeType: "40(8)",
+					Amount:     Baht(1000000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if got.TaxableIncome != Baht(340000) || got.Tax != Baht(19000) {
+			t.Errorf("got: %v, want taxableIncome: 340000, tax: 19000", got)
+		}
+	})
+
+	t.Run("Test totalIncome not equal sum of incomes", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(500000),
+			Incomes: []Income{
+				{
+					IncomeType: "40(1)",
+					Amount:     Baht(400000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "totalIncome must equal the sum of incomes"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test incomeType no have", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(9)",
+					Amount:     Baht(400000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "Not found incomeType"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test 40(8) 500,000 exempt from minimum tax", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(8)",
+					Amount:     Baht(500000),
+				},
+			},
+			Wht: Baht(1000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		want := &TaxMethod{
+			Method:         "progressive",
+			ProgressiveTax: 0,
+			MinimumTax:     Baht(2500),
+		}
+		if !reflect.DeepEqual(got.TaxMethod, want) {
+			t.Errorf("got: %v, want: %v", got.TaxMethod, want)
+		}
+
+		// 0.5% is 2,500, not more than 5,000, so only the progressive tax applies
+		if got.Tax != 0 || got.TaxRefund != Baht(1000) {
+			t.Errorf("got: %v, want tax: 0, taxRefund: 1000", got)
+		}
+	})
+
+	t.Run("Test 40(8) 2,000,000 use minimum tax", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(8)",
+					Amount:     Baht(2000000),
+				},
+			},
+			Wht: Baht(1000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(800000),
+				},
+			},
+		}
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		// 2,000,000 - expense 1,200,000 - personal 800,000 = 0 -> progressive 0
+		want := &TaxMethod{
+			Method:         "minimum",
+			ProgressiveTax: 0,
+			MinimumTax:     Baht(10000),
+		}
+		if !reflect.DeepEqual(got.TaxMethod, want) {
+			t.Errorf("got: %v, want: %v", got.TaxMethod, want)
+		}
+
+		if got.Tax != Baht(9000) {
+			t.Errorf("got: %v, want: %v", got.Tax, Baht(9000))
+		}
+	})
+
+	t.Run("Test 40(8) 1,000,000 keep progressive tax", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(8)",
+					Amount:     Baht(1000000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		want := &TaxMethod{
+			Method:         "progressive",
+			ProgressiveTax: Baht(19000),
+			MinimumTax:     Baht(5000),
+		}
+		if !reflect.DeepEqual(got.TaxMethod, want) {
+			t.Errorf("got: %v, want: %v", got.TaxMethod, want)
+		}
+	})
+
+	t.Run("Test 40(1) only no minimum tax", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			Incomes: []Income{
+				{
+					IncomeType: "40(1)",
+					Amount:     Baht(500000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if got.TaxMethod != nil {
+			t.Errorf("got: %v, want: nil", got.TaxMethod)
+		}
+	})
+}
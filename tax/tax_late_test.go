@@ -0,0 +1,120 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestLateTaxHandler(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		filingDate  string
+		paymentDate string
+		want        ResLateTax
+	}{
+		{
+			name:        "Test paid on due date",
+			paymentDate: "2025-03-31",
+			want:        ResLateTax{DueDate: "2025-03-31", Principal: Baht(29000), Total: Baht(29000)},
+		},
+		{
+			name:        "Test paid 2.5 months late",
+			paymentDate: "2025-06-15",
+			want:        ResLateTax{DueDate: "2025-03-31", LateMonths: 3, Principal: Baht(29000), Surcharge: Baht(1305), Penalty: Baht(2000), Total: Baht(32305)},
+		},
+		{
+			name:        "Test filed on time and paid 1 day late",
+			filingDate:  "2025-03-31",
+			paymentDate: "2025-04-01",
+			want:        ResLateTax{DueDate: "2025-03-31", LateMonths: 1, Principal: Baht(29000), Surcharge: Baht(435), Total: Baht(29435)},
+		},
+		{
+			name:        "Test surcharge capped at principal",
+			paymentDate: "2031-01-01",
+			want:        ResLateTax{DueDate: "2025-03-31", LateMonths: 70, Principal: Baht(29000), Surcharge: Baht(29000), Penalty: Baht(2000), Total: Baht(60000)},
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			e := echo.New()
+			MockReq := ReqLateTax{
+				ReqTax:      ReqTax{TaxYear: 2567, TotalIncome: Baht(500000)},
+				FilingDate:  v.filingDate,
+				PaymentDate: v.paymentDate,
+			}
+			reqBody, _ := json.Marshal(MockReq)
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations/late", bytes.NewBuffer(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := New(&mock)
+			handler.LateTaxHandler(c)
+
+			gotJson := rec.Body.Bytes()
+
+			var got ResLateTax
+			if err := json.Unmarshal(gotJson, &got); err != nil {
+				t.Errorf("failed to unmarshal json: %v", err)
+			}
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+			}
+
+			got.Calculation = ResTaxLevel{}
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("got: %v, want: %v", got, v.want)
+			}
+		})
+	}
+
+	t.Run("Test invalid paymentDate", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqLateTax{
+			ReqTax:      ReqTax{TotalIncome: Baht(500000)},
+			PaymentDate: "15/06/2025",
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/late", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.LateTaxHandler(c)
+
+		want := Err{Message: "paymentDate must be in YYYY-MM-DD format"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAcceptLanguage(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	levels := []struct {
+		language string
+		want     string
+	}{
+		{"en", "2,000,001 and above"},
+		{"en-US,en;q=0.9", "2,000,001 and above"},
+		{"th-TH", "2,000,001 ขึ้นไป"},
+		{"fr", "2,000,001 ขึ้นไป"},
+		{"", "2,000,001 ขึ้นไป"},
+	}
+	for _, v := range levels {
+		t.Run("Test tax level label for Accept-Language "+v.language, func(t *testing.T) {
+			e := echo.New()
+			reqBody, _ := json.Marshal(ReqTax{TotalIncome: Baht(3000000)})
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set("Accept-Language", v.language)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := New(&mock)
+			handler.TaxHandler(c)
+
+			gotJson := rec.Body.Bytes()
+
+			var got ResTaxLevel
+			if err := json.Unmarshal(gotJson, &got); err != nil {
+				t.Errorf("failed to unmarshal json: %v", err)
+			}
+
+			if len(got.TaxLevel) != 5 || got.TaxLevel[4].Level != v.want || got.MarginalLevel != v.want {
+				t.Errorf("got: %v, want: %v", got.TaxLevel, v.want)
+			}
+		})
+	}
+
+	t.Run("Test validation message in Thai", func(t *testing.T) {
+		e := echo.New()
+		reqBody, _ := json.Marshal(ReqTax{TotalIncome: 0})
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", "th")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "totalIncome ต้องมากกว่า 0"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test csv field message in Thai", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "donation"})
+		writer.Write([]string{"500000", "abc"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", "th")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		want := Err{Message: "ข้อมูลในคอลัมน์ donation ไม่ถูกต้อง"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test load error message in Thai", func(t *testing.T) {
+		e := echo.New()
+		reqBody, _ := json.Marshal(ReqTax{TaxYear: 2500, TotalIncome: Baht(500000)})
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", "th")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		year_mock := mock
+		year_mock.taxYears = []int{2567}
+		handler := New(&year_mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "ไม่พบปีภาษี 2500"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusNotFound)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test unreachable target message in Thai", func(t *testing.T) {
+		e := echo.New()
+		target := Baht(2_000_000_000_000)
+		reqBody, _ := json.Marshal(ReqReverseTax{TargetTax: &target})
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/reverse", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", "th")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.ReverseTaxHandler(c)
+
+		want := Err{Message: "ไม่สามารถคำนวนรายได้ให้ได้ภาษีตาม targetTax"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test validation message in English", func(t *testing.T) {
+		e := echo.New()
+		reqBody, _ := json.Marshal(ReqTax{TotalIncome: 0})
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", "en")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "totalIncome must be greater than 0"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
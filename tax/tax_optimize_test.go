@@ -0,0 +1,204 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestOptimizeDeductionHandler(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+			{
+				Type:   "K-Receipt",
+				Amount: Baht(50000),
+			},
+		},
+	}
+
+	t.Run("Test Income 500,000 fill donation and k-receipt", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(500000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/deductions/optimize", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.OptimizeDeductionHandler(c)
+
+		want := ResOptimize{
+			Tax:          Baht(29000),
+			OptimizedTax: Baht(14000),
+			Recommendations: []Recommendation{
+				{
+					AllowanceType:  "donation",
+					Limit:          Baht(100000),
+					Additional:     Baht(100000),
+					TaxSaved:       Baht(10000),
+					SavedPerBaht:   0.1,
+					MarginalSaving: 0.1,
+				},
+				{
+					AllowanceType:  "k-receipt",
+					Limit:          Baht(50000),
+					Additional:     Baht(50000),
+					TaxSaved:       Baht(5000),
+					SavedPerBaht:   0.1,
+					MarginalSaving: 0.1,
+				},
+			},
+		}
+		gotJson := rec.Body.Bytes()
+
+		var got ResOptimize
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test Income 250,000 stop at 0% bracket", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(250000),
+			Allowances: []Allowance{
+				{
+					AllowanceType: "donation",
+					Amount:        Baht(10000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/deductions/optimize", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.OptimizeDeductionHandler(c)
+
+		want := ResOptimize{
+			Tax:          Baht(3000),
+			OptimizedTax: 0,
+			Recommendations: []Recommendation{
+				{
+					AllowanceType:  "donation",
+					Current:        Baht(10000),
+					Limit:          Baht(100000),
+					Additional:     Baht(30000),
+					TaxSaved:       Baht(3000),
+					SavedPerBaht:   0.1,
+					MarginalSaving: 0.1,
+				},
+				{
+					AllowanceType: "k-receipt",
+					Limit:         Baht(50000),
+				},
+			},
+		}
+		gotJson := rec.Body.Bytes()
+
+		var got ResOptimize
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test donation limit follows cap_percent", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(500000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/deductions/optimize", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+				{
+					Type:        "Donation",
+					Amount:      Baht(100000),
+					Cap_percent: 10,
+				},
+				{
+					Type:   "K-Receipt",
+					Amount: Baht(50000),
+				},
+			},
+		}
+
+		handler := New(&mock)
+		handler.OptimizeDeductionHandler(c)
+
+		// k-receipt is deducted first, so donation is capped at
+		// (500,000 - 60,000 - 50,000) x 10% = 39,000
+		want := ResOptimize{
+			Tax:          Baht(29000),
+			OptimizedTax: Baht(20100),
+			Recommendations: []Recommendation{
+				{
+					AllowanceType:  "k-receipt",
+					Limit:          Baht(50000),
+					Additional:     Baht(50000),
+					TaxSaved:       Baht(5000),
+					SavedPerBaht:   0.1,
+					MarginalSaving: 0.1,
+				},
+				{
+					AllowanceType:  "donation",
+					Limit:          Baht(39000),
+					Additional:     Baht(39000),
+					TaxSaved:       Baht(3900),
+					SavedPerBaht:   0.1,
+					MarginalSaving: 0.1,
+				},
+			},
+		}
+		gotJson := rec.Body.Bytes()
+
+		var got ResOptimize
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
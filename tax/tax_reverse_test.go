@@ -0,0 +1,213 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestReverseTaxHandler(t *testing.T) {
+	t.Run("Test netIncome 471,000 output totalIncome 500,000", func(t *testing.T) {
+		e := echo.New()
+		net := Baht(471000)
+		MockReq := ReqReverseTax{
+			NetIncome: &net,
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/reverse", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+		}
+
+		handler := New(&mock)
+		handler.ReverseTaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResReverseTax
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		if got.TotalIncome != Baht(500000) || got.NetIncome != Baht(471000) || got.Tax != Baht(29000) {
+			t.Errorf("got: %v, want totalIncome: 500000, netIncome: 471000, tax: 29000", got)
+		}
+	})
+
+	t.Run("Test netIncome with donation 200,000", func(t *testing.T) {
+		e := echo.New()
+		net := Baht(481000)
+		MockReq := ReqReverseTax{
+			NetIncome: &net,
+			Allowances: []Allowance{
+				{
+					AllowanceType: "donation",
+					Amount:        Baht(200000),
+				},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/reverse", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
+				},
+			},
+		}
+
+		handler := New(&mock)
+		handler.ReverseTaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResReverseTax
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if got.TotalIncome != Baht(500000) || got.Tax != Baht(19000) {
+			t.Errorf("got: %v, want totalIncome: 500000, tax: 19000", got)
+		}
+	})
+
+	t.Run("Test targetTax 4,000 with wht 25,000", func(t *testing.T) {
+		e := echo.New()
+		target := Baht(4000)
+		MockReq := ReqReverseTax{
+			TargetTax: &target,
+			Wht:       Baht(25000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/reverse", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+		}
+
+		handler := New(&mock)
+		handler.ReverseTaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResReverseTax
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		// 499,999.95 already rounds up to 29,000, but the search is in whole baht.
+		if got.TotalIncome != Baht(500000) || got.Tax != Baht(4000) {
+			t.Errorf("got: %v, want totalIncome: 500000, tax: 4000", got)
+		}
+	})
+
+	t.Run("Test targetTax 29,000 with wht 1,000 in whole baht", func(t *testing.T) {
+		e := echo.New()
+		target := Baht(29000)
+		MockReq := ReqReverseTax{
+			TargetTax: &target,
+			Wht:       Baht(1000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/reverse", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+		}
+
+		handler := New(&mock)
+		handler.ReverseTaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResReverseTax
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if got.TotalIncome != Baht(510000) || got.Tax != Baht(29000) {
+			t.Errorf("got: %v, want totalIncome: 510000, tax: 29000", got)
+		}
+	})
+
+	t.Run("Test netIncome and targetTax together", func(t *testing.T) {
+		e := echo.New()
+		net := Baht(471000)
+		target := Baht(4000)
+		MockReq := ReqReverseTax{
+			NetIncome: &net,
+			TargetTax: &target,
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/reverse", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{}
+
+		handler := New(&mock)
+		handler.ReverseTaxHandler(c)
+
+		want := Err{Message: "Either netIncome or targetTax is required"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestSandboxTaxHandler(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+			{
+				Type:   "Donation",
+				Amount: Baht(100000),
+			},
+		},
+	}
+
+	t.Run("Test ad-hoc brackets with deduction overrides", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqSandbox{
+			ReqTax: ReqTax{
+				TotalIncome: Baht(500000),
+				Allowances: []Allowance{
+					{
+						AllowanceType: "solar-roof",
+						Amount:        Baht(30000),
+					},
+				},
+			},
+			Brackets: []Bracket{
+				{MinimumSalary: 0, MaximumSalary: Baht(100000), Rate: 0},
+				{MinimumSalary: Baht(100001), MaximumSalary: 0, Rate: 20},
+			},
+			Deductions: []DeductionOverride{
+				{Type: "Personal", Amount: Baht(100000)},
+				{Type: "Solar-Roof", Amount: Baht(20000)},
+			},
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/sandbox", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.SandboxTaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// 500,000 - personal 100,000 - solar-roof 20,000 = 380,000
+		// (380,000 - 100,000) x 20% = 56,000
+		if got.TaxableIncome != Baht(380000) || got.Tax != Baht(56000) {
+			t.Errorf("got: %v, want taxableIncome: 380000, tax: 56000", got)
+		}
+
+		want := []TaxLevel{
+			{Level: "0-100,000", Tax: 0},
+			{Level: "100,001 ขึ้นไป", Tax: Baht(56000)},
+		}
+		if !reflect.DeepEqual(got.TaxLevel, want) {
+			t.Errorf("got: %v, want: %v", got.TaxLevel, want)
+		}
+
+		if mock.dbDeduction[0].Amount != Baht(60000) {
+			t.Errorf("got: %v, want personal deduction unchanged", mock.dbDeduction[0].Amount)
+		}
+	})
+
+	widths := []struct {
+		name     string
+		brackets []Bracket
+		income   Money
+		want     Money
+	}{
+		{
+			name: "Test first bracket with a non-zero rate",
+			brackets: []Bracket{
+				{MinimumSalary: 0, MaximumSalary: Baht(150000), Rate: 5},
+				{MinimumSalary: Baht(150001), MaximumSalary: 0, Rate: 10},
+			},
+			// 150,000 x 5% + 50,000 x 10%
+			income: Baht(200000),
+			want:   Baht(12500),
+		},
+		{
+			name: "Test zero rate bracket after the first",
+			brackets: []Bracket{
+				{MinimumSalary: 0, MaximumSalary: Baht(100000), Rate: 10},
+				{MinimumSalary: Baht(100001), MaximumSalary: Baht(200000), Rate: 0},
+				{MinimumSalary: Baht(200001), MaximumSalary: 0, Rate: 20},
+			},
+			// 100,000 x 10% + 100,000 x 0% + 100,000 x 20%
+			income: Baht(300000),
+			want:   Baht(30000),
+		},
+	}
+	for _, v := range widths {
+		t.Run(v.name, func(t *testing.T) {
+			e := echo.New()
+			MockReq := ReqSandbox{
+				ReqTax:   ReqTax{TotalIncome: v.income + Baht(60000)},
+				Brackets: v.brackets,
+			}
+			reqBody, _ := json.Marshal(MockReq)
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations/sandbox", bytes.NewBuffer(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := New(&mock)
+			handler.SandboxTaxHandler(c)
+
+			var got ResTaxLevel
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Errorf("failed to unmarshal json: %v", err)
+			}
+
+			if got.TaxableIncome != v.income || got.Tax != v.want {
+				t.Errorf("got: %v, want taxableIncome: %v, tax: %v", got, v.income, v.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name     string
+		brackets []Bracket
+		want     string
+	}{
+		{
+			name: "Test brackets with gap",
+			brackets: []Bracket{
+				{MinimumSalary: 0, MaximumSalary: Baht(100000), Rate: 0},
+				{MinimumSalary: Baht(100002), MaximumSalary: 0, Rate: 20},
+			},
+			want: "brackets must not have gaps",
+		},
+		{
+			name: "Test brackets with overlap",
+			brackets: []Bracket{
+				{MinimumSalary: 0, MaximumSalary: Baht(100000), Rate: 0},
+				{MinimumSalary: Baht(90000), MaximumSalary: 0, Rate: 20},
+			},
+			want: "brackets must not overlap",
+		},
+		{
+			name: "Test brackets without open last bracket",
+			brackets: []Bracket{
+				{MinimumSalary: 0, MaximumSalary: Baht(100000), Rate: 0},
+			},
+			want: "last bracket must have no maximumSalary",
+		},
+		{
+			name: "Test brackets not starting at 0",
+			brackets: []Bracket{
+				{MinimumSalary: Baht(1), MaximumSalary: 0, Rate: 10},
+			},
+			want: "brackets must start at 0",
+		},
+	}
+	for _, v := range invalid {
+		t.Run(v.name, func(t *testing.T) {
+			e := echo.New()
+			MockReq := ReqSandbox{
+				ReqTax:   ReqTax{TotalIncome: Baht(500000)},
+				Brackets: v.brackets,
+			}
+			reqBody, _ := json.Marshal(MockReq)
+			req := httptest.NewRequest(http.MethodPost, "/tax/calculations/sandbox", bytes.NewBuffer(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := New(&mock)
+			handler.SandboxTaxHandler(c)
+
+			want := Err{Message: v.want}
+			gotJson := rec.Body.Bytes()
+
+			var got Err
+			if err := json.Unmarshal(gotJson, &got); err != nil {
+				t.Errorf("failed to unmarshal json: %v", err)
+			}
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got: %v, want: %v", got, want)
+			}
+		})
+	}
+}
@@ -8,27 +8,51 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/labstack/echo/v4"
 )
 
 type MockTax struct {
-	dbDeduction []DbDeduction
-	err         error
+	dbDeduction      []DbDeduction
+	dbDeductionGroup []DbDeductionGroup
+	taxYears         []int
+	err              error
 }
 
-func (m MockTax) GetTax() ([]DB, error) {
+func (m MockTax) GetTax(tax_year int) ([]DB, error) {
+	if len(m.taxYears) > 0 && !slices.Contains(m.taxYears, tax_year) {
+		return nil, m.err
+	}
+
 	return []DB{
-		{Minimum_salary: 0, Maximum_salary: 150000, Rate: 0},
-		{Minimum_salary: 150001, Maximum_salary: 500000, Rate: 10},
-		{Minimum_salary: 500001, Maximum_salary: 1000000, Rate: 15},
-		{Minimum_salary: 1000001, Maximum_salary: 2000000, Rate: 20},
-		{Minimum_salary: 2000001, Maximum_salary: 0, Rate: 35},
+		{Minimum_salary: 0, Maximum_salary: Baht(150000), Rate: 0},
+		{Minimum_salary: Baht(150001), Maximum_salary: Baht(500000), Rate: 10},
+		{Minimum_salary: Baht(500001), Maximum_salary: Baht(1000000), Rate: 15},
+		{Minimum_salary: Baht(1000001), Maximum_salary: Baht(2000000), Rate: 20},
+		{Minimum_salary: Baht(2000001), Maximum_salary: 0, Rate: 35},
 	}, m.err
 }
 
-func (m MockTax) GetTaxDeducationByType(deducation_type string) (DbDeduction, error) {
+func (m MockTax) GetTaxYears() ([]int, error) {
+	return m.taxYears, m.err
+}
+
+func (m MockTax) GetTaxDeducations(tax_year int) ([]DbDeduction, error) {
+	return m.dbDeduction, m.err
+}
+
+func (m MockTax) GetDeducationTypes() ([]string, error) {
+	var deducation_types []string
+	for _, v := range m.dbDeduction {
+		deducation_types = append(deducation_types, v.Type)
+	}
+
+	return deducation_types, m.err
+}
+
+func (m MockTax) GetTaxDeducationByType(tax_year int, deducation_type string) (DbDeduction, error) {
 	for _, v := range m.dbDeduction {
 		if v.Type == deducation_type {
 			return v, nil
@@ -37,20 +61,37 @@ func (m MockTax) GetTaxDeducationByType(deducation_type string) (DbDeduction, er
 	return DbDeduction{}, m.err
 }
 
-func (m MockTax) SetTaxDeducationByType(deducation_type string, amount float64) error {
+func (m MockTax) SetTaxDeducationByType(tax_year int, deducation_type string, amount Money) error {
 	return m.err
 }
 
+func (m MockTax) GetDeductionGroups(tax_year int) ([]DbDeductionGroup, error) {
+	return m.dbDeductionGroup, m.err
+}
+
+func (m MockTax) GetExpenseRules(tax_year int) ([]DbExpenseRule, error) {
+	return []DbExpenseRule{
+		{Income_type: "40(1)", Expense_group: "40(1)-40(2)", Rate: 50, Maximum_amount: Baht(100000)},
+		{Income_type: "40(2)", Expense_group: "40(1)-40(2)", Rate: 50, Maximum_amount: Baht(100000)},
+		{Income_type: "40(3)", Expense_group: "40(3)", Rate: 50, Maximum_amount: Baht(100000)},
+		{Income_type: "40(4)", Expense_group: "40(4)", Rate: 0},
+		{Income_type: "40(5)", Expense_group: "40(5)", Rate: 30},
+		{Income_type: "40(6)", Expense_group: "40(6)", Rate: 30},
+		{Income_type: "40(7)", Expense_group: "40(7)", Rate: 60},
+		{Income_type: "40(8)", Expense_group: "40(8)", Rate: 60},
+	}, m.err
+}
+
 func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 500000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         0.0,
+			TotalIncome: Baht(500000),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -64,7 +105,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -73,28 +118,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       29000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(29000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(440000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: 0, Applied: 0},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 5.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   29000.0,
+					Tax:   Baht(29000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -113,12 +168,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 1000000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 1000000.0,
-			Wht:         0.0,
+			TotalIncome: Baht(1000000),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -132,7 +187,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -141,28 +200,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       101000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(101000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(940000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: 0, Applied: 0},
+				},
+				MarginalLevel: "500,001-1,000,000",
+				MarginalRate:  15,
+				EffectiveRate: 10.1,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   35000.0,
+					Tax:   Baht(35000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   66000.0,
+					Tax:   Baht(66000),
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -181,12 +250,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 2000000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 2000000.0,
-			Wht:         0.0,
+			TotalIncome: Baht(2000000),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -200,7 +269,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -209,28 +282,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       298000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(298000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(1940000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: 0, Applied: 0},
+				},
+				MarginalLevel: "1,000,001-2,000,000",
+				MarginalRate:  20,
+				EffectiveRate: 14.9,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   35000.0,
+					Tax:   Baht(35000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   75000.0,
+					Tax:   Baht(75000),
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   188000.0,
+					Tax:   Baht(188000),
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -249,12 +332,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 3000000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 3000000.0,
-			Wht:         0.0,
+			TotalIncome: Baht(3000000),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -268,7 +351,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -277,28 +364,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       639000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(639000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(2940000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: 0, Applied: 0},
+				},
+				MarginalLevel: "2,000,001 ขึ้นไป",
+				MarginalRate:  35,
+				EffectiveRate: 21.3,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   35000.0,
+					Tax:   Baht(35000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   75000.0,
+					Tax:   Baht(75000),
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   200000.0,
+					Tax:   Baht(200000),
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   329000.0,
+					Tax:   Baht(329000),
 				},
 			},
 		}
@@ -317,12 +414,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income wht great tax", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         30000.0,
+			TotalIncome: Baht(500000),
+			Wht:         Baht(30000),
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -336,7 +433,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -345,28 +446,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       0.0,
-			TaxRefund: 1000.0,
+			Tax:       0,
+			TaxRefund: Baht(1000),
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(440000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: 0, Applied: 0},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 5.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   29000.0,
+					Tax:   Baht(29000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -385,12 +496,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 50,000 and wht 25,000 output tax 4,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         25000.0,
+			TotalIncome: Baht(500000),
+			Wht:         Baht(25000),
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        0.0,
+					Amount:        0,
 				},
 			},
 		}
@@ -404,7 +515,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
+				},
+				{
+					Type:   "Donation",
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -413,28 +528,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       4000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(4000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(440000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: 0, Applied: 0},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 5.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   29000.0,
+					Tax:   Baht(29000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -453,12 +578,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 50,000 and donation 200,000 output tax 19,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         0.0,
+			TotalIncome: Baht(500000),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        200000.0,
+					Amount:        Baht(200000),
 				},
 			},
 		}
@@ -472,14 +597,14 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					ID:             1,
 					Type:           "Donation",
 					Minimum_amount: 0,
-					Maximum_amount: 100000,
-					Amount:         100000,
+					Maximum_amount: Baht(100000),
+					Amount:         Baht(100000),
 					Created_at:     "2021-09-01",
 					Updated_at:     "2021-09-01",
 				},
@@ -490,28 +615,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       19000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(19000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(340000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: Baht(200000), Applied: Baht(100000)},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 3.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   19000.0,
+					Tax:   Baht(19000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -530,12 +665,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 50,000 and donation 200,000 and Wht 20,000 output taxRefund 1,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         20000.0,
+			TotalIncome: Baht(500000),
+			Wht:         Baht(20000),
 			Allowances: []Allowance{
 				{
 					AllowanceType: "donation",
-					Amount:        200000.0,
+					Amount:        Baht(200000),
 				},
 			},
 		}
@@ -549,11 +684,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -562,28 +697,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       0.0,
-			TaxRefund: 1000.0,
+			Tax:       0,
+			TaxRefund: Baht(1000),
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(340000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "donation", Requested: Baht(200000), Applied: Baht(100000)},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 3.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   19000.0,
+					Tax:   Baht(19000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -602,12 +747,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 50,000 and K-Receipt 100,000 output tax 24,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         0.0,
+			TotalIncome: Baht(500000),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "k-receipt",
-					Amount:        100000.0,
+					Amount:        Baht(100000),
 				},
 			},
 		}
@@ -621,11 +766,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "K-Receipt",
-					Amount: 50000,
+					Amount: Baht(50000),
 				},
 			},
 		}
@@ -634,28 +779,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       24000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(24000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(390000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "k-receipt", Requested: Baht(100000), Applied: Baht(50000)},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 4.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   24000.0,
+					Tax:   Baht(24000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -674,12 +829,12 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test Income 50,000 and K-Receipt 100,000 and have Wht 30,000 output taxRefund 6,000", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         30000.0,
+			TotalIncome: Baht(500000),
+			Wht:         Baht(30000),
 			Allowances: []Allowance{
 				{
 					AllowanceType: "k-receipt",
-					Amount:        100000.0,
+					Amount:        Baht(100000),
 				},
 			},
 		}
@@ -693,11 +848,11 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "K-Receipt",
-					Amount: 50000,
+					Amount: Baht(50000),
 				},
 			},
 		}
@@ -706,28 +861,38 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       0.0,
-			TaxRefund: 6000.0,
+			Tax:       0,
+			TaxRefund: Baht(6000),
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(390000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "k-receipt", Requested: Baht(100000), Applied: Baht(50000)},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 4.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   24000.0,
+					Tax:   Baht(24000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
@@ -746,16 +911,16 @@ func TestTaxHandler(t *testing.T) {
 	t.Run("Test case stroy 6", func(t *testing.T) {
 		e := echo.New()
 		MockReq := ReqTax{
-			TotalIncome: 500000.0,
-			Wht:         0.0,
+			TotalIncome: Baht(500000),
+			Wht:         0,
 			Allowances: []Allowance{
 				{
 					AllowanceType: "k-receipt",
-					Amount:        200000.0,
+					Amount:        Baht(200000),
 				},
 				{
 					AllowanceType: "donation",
-					Amount:        100000.0,
+					Amount:        Baht(100000),
 				},
 			},
 		}
@@ -769,15 +934,15 @@ func TestTaxHandler(t *testing.T) {
 			dbDeduction: []DbDeduction{
 				{
 					Type:   "Personal",
-					Amount: 60000,
+					Amount: Baht(60000),
 				},
 				{
 					Type:   "K-Receipt",
-					Amount: 50000,
+					Amount: Baht(50000),
 				},
 				{
 					Type:   "Donation",
-					Amount: 100000,
+					Amount: Baht(100000),
 				},
 			},
 		}
@@ -786,28 +951,39 @@ func TestTaxHandler(t *testing.T) {
 		handler.TaxHandler(c)
 
 		want := ResTaxLevel{
-			Tax:       14000.0,
-			TaxRefund: 0.0,
+			Tax:       Baht(14000),
+			TaxRefund: 0,
+			TaxDetail: TaxDetail{
+				TaxableIncome: Baht(290000),
+				Deductions: []AppliedDeduction{
+					{Type: "personal", Requested: Baht(60000), Applied: Baht(60000)},
+					{Type: "k-receipt", Requested: Baht(200000), Applied: Baht(50000)},
+					{Type: "donation", Requested: Baht(100000), Applied: Baht(100000)},
+				},
+				MarginalLevel: "150,001-500,000",
+				MarginalRate:  10,
+				EffectiveRate: 2.8,
+			},
 			TaxLevel: []TaxLevel{
 				{
 					Level: "0-150,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "150,001-500,000",
-					Tax:   14000.0,
+					Tax:   Baht(14000),
 				},
 				{
 					Level: "500,001-1,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "1,000,001-2,000,000",
-					Tax:   0.0,
+					Tax:   0,
 				},
 				{
 					Level: "2,000,001 ขึ้นไป",
-					Tax:   0.0,
+					Tax:   0,
 				},
 			},
 		}
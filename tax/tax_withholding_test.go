@@ -0,0 +1,203 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestWithholdingHandler(t *testing.T) {
+	mock := MockTax{
+		dbDeduction: []DbDeduction{
+			{
+				Type:   "Personal",
+				Amount: Baht(60000),
+			},
+		},
+	}
+
+	t.Run("Test month 1 salary 50,000 spread over 12 months", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqWithholding{
+			Month:  1,
+			Salary: Baht(50000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/withholdings/monthly", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.WithholdingHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResWithholding
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// 600,000 - expense 100,000 - personal 60,000 = 440,000, tax 29,000
+		want := ResWithholding{
+			Month:             1,
+			RemainingMonths:   12,
+			AnnualIncome:      Baht(600000),
+			AnnualTax:         Baht(29000),
+			SalaryWithholding: 241667,
+			Withholding:       241667,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test month 7 with bonus and under withheld ytd", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqWithholding{
+			Month:     7,
+			Salary:    Baht(50000),
+			Bonus:     Baht(100000),
+			YtdIncome: Baht(300000),
+			YtdWht:    Baht(12000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/withholdings/monthly", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.WithholdingHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResWithholding
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		// salary tax (29,000 - 12,000) / 6, bonus tax 41,000 - 29,000 at once
+		want := ResWithholding{
+			Month:             7,
+			RemainingMonths:   6,
+			AnnualIncome:      Baht(700000),
+			AnnualTax:         Baht(41000),
+			YtdWht:            Baht(12000),
+			SalaryWithholding: 283333,
+			BonusWithholding:  Baht(12000),
+			Withholding:       1483333,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test over withheld ytd withholds nothing", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqWithholding{
+			Month:     12,
+			Salary:    Baht(50000),
+			YtdIncome: Baht(550000),
+			YtdWht:    Baht(30000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/withholdings/monthly", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.WithholdingHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResWithholding
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if got.Withholding != 0 || got.AnnualTax != Baht(29000) {
+			t.Errorf("got: %v, want withholding: 0, annualTax: 29000", got)
+		}
+	})
+
+	t.Run("Test month out of range", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqWithholding{
+			Month:  13,
+			Salary: Baht(50000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/withholdings/monthly", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.WithholdingHandler(c)
+
+		want := Err{Message: "month must be between 1 and 12"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test salary too large", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqWithholding{
+			Month:  1,
+			Salary: Baht(9_000_000_000_000_000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/withholdings/monthly", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := New(&mock)
+		handler.WithholdingHandler(c)
+
+		want := Err{Message: "salary and bonus must not be more than 1,000,000,000,000"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+}
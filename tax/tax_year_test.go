@@ -0,0 +1,282 @@
+//go:build unit
+
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTaxYearHandler(t *testing.T) {
+	t.Run("Test taxYear 2566", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TaxYear:     2566,
+			TotalIncome: Baht(500000),
+			Wht:         0,
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+			taxYears: []int{2566, 2567},
+		}
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		gotJson := rec.Body.Bytes()
+
+		var got ResTaxLevel
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		if got.Tax != Baht(29000) {
+			t.Errorf("got: %v, want: %v", got.Tax, 29000.0)
+		}
+	})
+
+	t.Run("Test default taxYear is latest configured year", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TotalIncome: Baht(500000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		// the current year has no rates yet and a future year already does
+		current := currentTaxYear()
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+			taxYears: []int{current - 2, current - 1, current + 1},
+		}
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		if got := handler.defaultTaxYear(); got != current-1 {
+			t.Errorf("got: %v, want: %v", got, current-1)
+		}
+	})
+
+	t.Run("Test taxYear not found", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TaxYear:     2500,
+			TotalIncome: Baht(500000),
+			Wht:         0,
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+			taxYears: []int{2566, 2567},
+		}
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "tax year not found: 2500"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusNotFound)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test taxYear less than 0", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqTax{
+			TaxYear:     -1,
+			TotalIncome: Baht(500000),
+			Wht:         0,
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{}
+
+		handler := New(&mock)
+		handler.TaxHandler(c)
+
+		want := Err{Message: "taxYear must be greater than 0"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusBadRequest)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test csv taxYear not found", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "taxYear"})
+		writer.Write([]string{"500000", "0", "2567"})
+		writer.Write([]string{"600000", "0", "2500"})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+			taxYears: []int{2567},
+		}
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		want := Err{Message: "tax year not found: 2500"}
+		gotJson := rec.Body.Bytes()
+
+		var got Err
+		if err := json.Unmarshal(gotJson, &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusNotFound)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	})
+
+	t.Run("Test csv empty taxYear uses latest configured year", func(t *testing.T) {
+		e := echo.New()
+
+		body := new(bytes.Buffer)
+		writer := csv.NewWriter(body)
+		writer.Write([]string{"totalIncome", "wht", "taxYear"})
+		writer.Write([]string{"500000", "0", ""})
+		writer.Flush()
+
+		req := httptest.NewRequest(http.MethodPost, "/tax/calculations/upload-csv?strict=true", body)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			dbDeduction: []DbDeduction{
+				{
+					Type:   "Personal",
+					Amount: Baht(60000),
+				},
+			},
+			taxYears: []int{2566, 2567},
+		}
+
+		handler := New(&mock)
+		handler.UploadCSVHandler(c)
+
+		var got ResAllCsv
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Errorf("failed to unmarshal json: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusOK)
+		}
+
+		if len(got.Taxes) != 1 || got.Taxes[0].Tax != Baht(29000) {
+			t.Errorf("got: %v, want tax: 29000", got.Taxes)
+		}
+	})
+
+	t.Run("Test set personal deduction taxYear not found", func(t *testing.T) {
+		e := echo.New()
+		MockReq := ReqAmount{
+			TaxYear: 2500,
+			Amount:  Baht(70000),
+		}
+		reqBody, _ := json.Marshal(MockReq)
+		req := httptest.NewRequest(http.MethodPost, "/admin/deductions/personal", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mock := MockTax{
+			taxYears: []int{2567},
+		}
+
+		handler := New(&mock)
+		handler.TaxDeducateHandler(c)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("got: %v, want: %v", rec.Code, http.StatusNotFound)
+		}
+	})
+}
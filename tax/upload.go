@@ -0,0 +1,421 @@
+package tax
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// csvFile is one csv of an upload with its validated header. The name is
+// empty for a raw request body.
+type csvFile struct {
+	name     string
+	body     io.Closer
+	reader   *csv.Reader
+	head     []string
+	position map[string]int
+}
+
+// csvUpload holds what every row of one upload needs: the files still to be
+// read, the output options, the calculators loaded so far and the tax years
+// that were not found, so rows of an unknown year do not load it again.
+type csvUpload struct {
+	t           Tax
+	files       []csvFile
+	current     int
+	printer     *message.Printer
+	lang        language.Tag
+	taxYear     int
+	taxLevel    bool
+	strict      bool
+	calculators map[int]Calculator
+	notFound    map[int]error
+}
+
+// csvFiles opens the csv files of the request and validates their headers:
+// every taxFile of a multipart form, or else the raw body as a single file.
+// On failure it returns the status to answer with and the error.
+func (t Tax) csvFiles(c echo.Context, printer *message.Printer) ([]csvFile, int, Err) {
+	req := c.Request()
+	media, params, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if media != echo.MIMEMultipartForm {
+		file, msg := t.csvFile("", req.Body, csvCharset(c, params), printer)
+		if msg.Message != "" {
+			return nil, http.StatusBadRequest, msg
+		}
+
+		return []csvFile{file}, http.StatusOK, Err{}
+	}
+
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, max_csv_upload_size)
+	if err := req.ParseMultipartForm(max_csv_file_size); err != nil {
+		var too_large *http.MaxBytesError
+		if errors.As(err, &too_large) {
+			return nil, http.StatusRequestEntityTooLarge, Err{Message: printer.Sprintf("upload must not be larger than %d MB", max_csv_upload_size>>20)}
+		}
+		return nil, http.StatusBadRequest, Err{Message: printer.Sprintf("invalid multipart form")}
+	}
+
+	headers := req.MultipartForm.File["taxFile"]
+	if len(headers) == 0 {
+		return nil, http.StatusBadRequest, Err{Message: printer.Sprintf("taxFile is required")}
+	}
+	var files []csvFile
+	for _, header := range headers {
+		if header.Size > max_csv_file_size {
+			closeCsvFiles(files)
+			return nil, http.StatusRequestEntityTooLarge, Err{Message: printer.Sprintf("taxFile %s must not be larger than %d MB", header.Filename, max_csv_file_size>>20)}
+		}
+		body, err := header.Open()
+		if err != nil {
+			closeCsvFiles(files)
+			return nil, http.StatusBadRequest, Err{Message: printer.Sprintf("failed to read csv")}
+		}
+
+		_, params, _ := mime.ParseMediaType(header.Header.Get(echo.HeaderContentType))
+		file, msg := t.csvFile(header.Filename, body, csvCharset(c, params), printer)
+		file.body = body
+		files = append(files, file)
+		if msg.Message != "" {
+			closeCsvFiles(files)
+			return nil, http.StatusBadRequest, msg
+		}
+	}
+
+	return files, http.StatusOK, Err{}
+}
+
+// csvFile reads and validates the header of one csv, once it is decoded to
+// UTF-8 and its delimiter is known.
+func (t Tax) csvFile(name string, body io.Reader, charset string, printer *message.Printer) (csvFile, Err) {
+	decoded, ok := decodeCsv(body, charset)
+	if !ok {
+		return csvFile{}, Err{Message: printer.Sprintf("unsupported charset %s", charset)}
+	}
+	reader := csv.NewReader(decoded)
+	reader.Comma = csvDelimiter(decoded)
+	head, err := reader.Read()
+	if err != nil {
+		return csvFile{}, Err{Message: printer.Sprintf("failed to read csv")}
+	}
+
+	position, msg := t.validateCsv(head, printer)
+	if msg.Message != "" {
+		return csvFile{}, msg
+	}
+
+	return csvFile{name: name, reader: reader, head: head, position: position}, Err{}
+}
+
+// csvCharset is the charset a csv was sent in: the charset query parameter,
+// or else the charset of its Content-Type. Empty means it has to be guessed.
+func csvCharset(c echo.Context, params map[string]string) string {
+	if charset := c.QueryParam("charset"); charset != "" {
+		return charset
+	}
+
+	return params["charset"]
+}
+
+func closeCsvFiles(files []csvFile) {
+	for _, file := range files {
+		if file.body != nil {
+			file.body.Close()
+		}
+	}
+}
+
+// tax calculates one csv row. On failure it returns the status the strict mode
+// answers with and the error.
+func (u *csvUpload) tax(file csvFile, record []string) (ResCsvTax, int, CsvError) {
+	req, msg := u.t.csvReq(file.position, record, u.taxYear, u.printer)
+	if msg.Reason != "" {
+		return ResCsvTax{}, http.StatusBadRequest, msg
+	}
+
+	if err, ok := u.notFound[req.TaxYear]; ok {
+		return ResCsvTax{}, errStatus(err), CsvError{Column: "taxYear", Reason: errMessage(err, req.TaxYear, u.printer)}
+	}
+	calculator, err := u.t.cachedCalculator(u.calculators, req.TaxYear, u.lang)
+	if errors.Is(err, ErrTaxYearNotFound) {
+		u.notFound[req.TaxYear] = err
+	}
+	if err != nil {
+		return ResCsvTax{}, errStatus(err), CsvError{Column: "taxYear", Reason: errMessage(err, req.TaxYear, u.printer)}
+	}
+	if msg := u.t.validateCsvReq(req, calculator.AllowanceTypes(), u.printer); msg.Reason != "" {
+		return ResCsvTax{}, http.StatusBadRequest, msg
+	}
+
+	res := calculator.Calculate(req)
+	res_csv := ResCsvTax{
+		File:        file.name,
+		TotalIncome: req.TotalIncome,
+		Tax:         res.Tax,
+		TaxRefund:   res.TaxRefund,
+		TaxDetail:   res.TaxDetail,
+	}
+	if u.taxLevel {
+		res_csv.TaxLevel = res.TaxLevel
+	}
+
+	return res_csv, http.StatusOK, CsvError{}
+}
+
+// csvRow is one row of an uploaded csv after it has been calculated. When the
+// row can not be calculated, err holds why and status the code the strict
+// mode answers with.
+type csvRow struct {
+	file   csvFile
+	record []string
+	tax    ResCsvTax
+	status int
+	err    CsvError
+}
+
+// next reads and calculates the next row, going through the files in turn.
+// The error is io.EOF after the last row of the last file, or a read error
+// that stops the upload.
+func (u *csvUpload) next() (csvRow, error) {
+	for ; u.current < len(u.files); u.current++ {
+		row, err := u.read(u.files[u.current])
+		if !errors.Is(err, io.EOF) {
+			return row, err
+		}
+	}
+
+	return csvRow{}, io.EOF
+}
+
+// read reads and calculates the next row of one file. A row with the wrong
+// number of fields is only a read error in strict mode; otherwise it is
+// rejected like any other bad row.
+func (u *csvUpload) read(file csvFile) (csvRow, error) {
+	record, err := file.reader.Read()
+	if err != nil && (u.strict || !errors.Is(err, csv.ErrFieldCount)) {
+		return csvRow{}, err
+	}
+	row, _ := file.reader.FieldPos(0)
+	if err != nil {
+		msg := CsvError{File: file.name, Row: row, Reason: u.printer.Sprintf("wrong number of fields")}
+		return csvRow{file: file, record: record, status: http.StatusBadRequest, err: msg}, nil
+	}
+
+	res_csv, status, msg := u.tax(file, record)
+	if msg.Reason != "" {
+		msg.File = file.name
+		msg.Row = row
+	} else {
+		res_csv.Row = row
+	}
+
+	return csvRow{file: file, record: record, tax: res_csv, status: status, err: msg}, nil
+}
+
+// stops tells whether a rejected row ends the whole upload. That is every
+// rejected row in strict mode, and otherwise only a failure to load the tax
+// year, which no other row could do better at.
+func (u *csvUpload) stops(status int) bool {
+	return u.strict || status == http.StatusInternalServerError
+}
+
+// stream writes the result or the error of every row as one line of NDJSON as
+// soon as it is calculated, so memory stays bounded by a single row whatever
+// the size of the file, and ends with a line holding the summary, in strict
+// mode too, so a finished stream can be told from a cut off one. The first
+// row is read before anything is written, so an empty file still gets a 400.
+// A failure that stops the upload after that can only end the stream with a
+// line holding the error.
+func (u *csvUpload) stream(c echo.Context) error {
+	row, err := u.next()
+	if errors.Is(err, io.EOF) {
+		return c.JSON(http.StatusBadRequest, Err{Message: u.printer.Sprintf("invalid csv have not value")})
+	} else if err != nil {
+		return c.JSON(http.StatusBadRequest, Err{Message: u.printer.Sprintf("failed to read csv")})
+	}
+	if row.err.Reason != "" && u.stops(row.status) {
+		return c.JSON(row.status, Err{Message: row.err.Reason})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, mime_ndjson)
+	res.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(res)
+	summary := CsvSummary{}
+	for {
+		if row.err.Reason != "" {
+			err = encoder.Encode(row.err)
+			summary.Rejected++
+		} else {
+			err = encoder.Encode(row.tax)
+			summary.Accepted++
+		}
+		if err != nil {
+			return err
+		}
+		res.Flush()
+
+		row, err = u.next()
+		if errors.Is(err, io.EOF) {
+			return encoder.Encode(summary)
+		} else if err != nil {
+			return encoder.Encode(Err{Message: u.printer.Sprintf("failed to read csv")})
+		}
+		if row.err.Reason != "" && u.stops(row.status) {
+			return encoder.Encode(Err{Message: row.err.Reason})
+		}
+	}
+}
+
+// table answers with the uploaded csv itself, every row followed by its tax
+// and taxRefund, for clients that asked for text/csv. The columns are those of
+// every file in the order they first show up, led by a file column when more
+// than one file was uploaded. With taxLevel there is also a column for the tax
+// of every bracket, in the same first-seen order, since rows of different tax
+// years may not share the same brackets. An error column is added only when
+// some row was rejected.
+func (u *csvUpload) table(c echo.Context, rows []csvRow) error {
+	var head []string
+	for _, file := range u.files {
+		for _, column := range file.head {
+			if !slices.Contains(head, column) {
+				head = append(head, column)
+			}
+		}
+	}
+	var levels []string
+	rejected := false
+	for _, row := range rows {
+		if row.err.Reason != "" {
+			rejected = true
+		}
+		for _, level := range row.tax.TaxLevel {
+			if !slices.Contains(levels, level.Level) {
+				levels = append(levels, level.Level)
+			}
+		}
+	}
+
+	var columns []string
+	if len(u.files) > 1 {
+		columns = append(columns, "file")
+	}
+	columns = append(columns, head...)
+	columns = append(columns, "tax", "taxRefund")
+	columns = append(columns, levels...)
+	if rejected {
+		columns = append(columns, "error")
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, mime_csv)
+	res.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(res)
+	writer.Write(columns)
+	for _, row := range rows {
+		record := make([]string, 0, len(columns))
+		if len(u.files) > 1 {
+			record = append(record, row.file.name)
+		}
+		for _, column := range head {
+			if i, ok := row.file.position[column]; ok && i < len(row.record) {
+				record = append(record, row.record[i])
+			} else {
+				record = append(record, "")
+			}
+		}
+		if row.err.Reason != "" {
+			record = append(record, make([]string, len(columns)-len(record)-1)...)
+			record = append(record, row.err.Reason)
+			writer.Write(record)
+			continue
+		}
+
+		record = append(record, row.tax.Tax.String(), row.tax.TaxRefund.String())
+		for _, level := range levels {
+			i := slices.IndexFunc(row.tax.TaxLevel, func(l TaxLevel) bool {
+				return l.Level == level
+			})
+			if i < 0 {
+				record = append(record, "")
+			} else {
+				record = append(record, row.tax.TaxLevel[i].Tax.String())
+			}
+		}
+		if rejected {
+			record = append(record, "")
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// negotiate picks the first of offers with the highest quality in the Accept
+// header of the request. The most specific media range that matches an offer
+// gives its q, so "text/csv;q=0, */*" rules out csv, and an offer with q=0 is
+// not acceptable. Without an Accept header the first offer is picked. It
+// returns "" when no offer is acceptable.
+func negotiate(c echo.Context, offers ...string) string {
+	header := c.Request().Header.Get(echo.HeaderAccept)
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	best, best_q := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, v := range strings.Split(header, ",") {
+			media, params, err := mime.ParseMediaType(v)
+			if err != nil {
+				continue
+			}
+			if s := mediaSpecificity(media, offer); s > specificity {
+				q, specificity = acceptQuality(params), s
+			}
+		}
+		if q > best_q {
+			best, best_q = offer, q
+		}
+	}
+
+	return best
+}
+
+// mediaSpecificity is 2 when the media range of an Accept header is offer
+// itself, 1 for its type/*, 0 for */* and -1 when it does not match.
+func mediaSpecificity(media string, offer string) int {
+	kind, _, _ := strings.Cut(offer, "/")
+	switch media {
+	case offer:
+		return 2
+	case kind + "/*":
+		return 1
+	case "*/*":
+		return 0
+	}
+
+	return -1
+}
+
+// acceptQuality is the q parameter of a media range, 1 when it is missing or
+// invalid.
+func acceptQuality(params map[string]string) float64 {
+	q, err := strconv.ParseFloat(params["q"], 64)
+	if err != nil || q < 0 || q > 1 {
+		return 1
+	}
+
+	return q
+}
@@ -4,41 +4,144 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
 
-func (t Tax) validateReq(req ReqTax) (bool, Err) {
-	if req.TotalIncome <= 0 {
-		return false, Err{Message: "totalIncome must be greater than 0"}
+// currentTaxYear returns the current tax year in the Thai Buddhist calendar.
+func currentTaxYear() int {
+	return time.Now().Year() + 543
+}
+
+// defaultTaxYear is the tax year of a request that does not give one: the
+// latest year in tax_rates that is not after the current year, so requests
+// keep working in a new year until its rates are seeded. It falls back to the
+// current year when no such year is configured or the years can not be read;
+// loading that year then reports the problem.
+func (t Tax) defaultTaxYear() int {
+	current := currentTaxYear()
+	tax_years, err := t.info.GetTaxYears()
+	if err != nil {
+		return current
+	}
+
+	tax_year := 0
+	for _, v := range tax_years {
+		if v <= current && v > tax_year {
+			tax_year = v
+		}
+	}
+	if tax_year == 0 {
+		return current
+	}
+
+	return tax_year
+}
+
+func (t Tax) validateReq(req ReqTax, printer *message.Printer) (bool, Err) {
+	if req.TaxYear < 0 {
+		return false, Err{Message: printer.Sprintf("taxYear must be greater than 0")}
+	}
+
+	if ok, err := t.validateIncomes(req, printer); !ok {
+		return false, err
+	}
+
+	if req.grossIncome() <= 0 {
+		return false, Err{Message: printer.Sprintf("totalIncome must be greater than 0")}
+	}
+
+	if req.TotalIncome > Baht(max_amount_baht) {
+		return false, Err{Message: printer.Sprintf("totalIncome must not be more than %d", max_amount_baht)}
 	}
 
 	if req.Wht < 0 {
-		return false, Err{Message: "Wht must be greater than 0"}
+		return false, Err{Message: printer.Sprintf("Wht must be greater than 0")}
+	}
+
+	if req.Wht > req.grossIncome() {
+		return false, Err{Message: printer.Sprintf("Wht must be less than totalIncome")}
+	}
+
+	if req.Pnd94 < 0 {
+		return false, Err{Message: printer.Sprintf("pnd94 must be greater than 0")}
+	}
+
+	if req.Pnd94 > Baht(max_amount_baht) {
+		return false, Err{Message: printer.Sprintf("pnd94 must not be more than %d", max_amount_baht)}
+	}
+
+	return true, Err{}
+}
+
+func (t Tax) validateHalfYearReq(req ReqTax, printer *message.Printer) (bool, Err) {
+	if ok, err := t.validateReq(req, printer); !ok {
+		return false, err
+	}
+
+	if len(req.Incomes) == 0 {
+		return false, Err{Message: printer.Sprintf("incomes are required for the half-year return")}
+	}
+
+	for _, v := range req.Incomes {
+		if ok := slices.Contains(half_year_income_types, v.IncomeType); !ok {
+			return false, Err{Message: printer.Sprintf("half-year return only accepts 40(5) to 40(8) incomes")}
+		}
+	}
+
+	if req.Pnd94 != 0 {
+		return false, Err{Message: printer.Sprintf("pnd94 is only credited in the annual return")}
+	}
+
+	return true, Err{}
+}
+
+// validateIncomes also keeps the sum of the incomes within max_amount_baht,
+// checking it as it goes so the sum itself can not overflow.
+func (t Tax) validateIncomes(req ReqTax, printer *message.Printer) (bool, Err) {
+	var total Money
+	for _, v := range req.Incomes {
+		if ok := slices.Contains(income_types, v.IncomeType); !ok {
+			return false, Err{Message: printer.Sprintf("Not found incomeType")}
+		}
+		if v.Amount < 0 {
+			return false, Err{Message: printer.Sprintf("Income amount must be greater than 0")}
+		}
+		if v.Amount > Baht(max_amount_baht)-total {
+			return false, Err{Message: printer.Sprintf("totalIncome must not be more than %d", max_amount_baht)}
+		}
+		total += v.Amount
 	}
 
-	if req.Wht > req.TotalIncome {
-		return false, Err{Message: "Wht must be less than totalIncome"}
+	if len(req.Incomes) > 0 && req.TotalIncome != 0 && req.TotalIncome != req.grossIncome() {
+		return false, Err{Message: printer.Sprintf("totalIncome must equal the sum of incomes")}
 	}
 
-	len_allowances := len(req.Allowances)
-	if len_allowances > 2 {
-		return false, Err{Message: "Allowances must be less than or equal to 2"}
+	return true, Err{}
+}
+
+// validateAllowances checks allowances against the allowance types that are
+// configured in tax_deductions, so it has to run after they are loaded.
+func (t Tax) validateAllowances(allowances []Allowance, allowance_type []string, printer *message.Printer) (bool, Err) {
+	len_allowances := len(allowances)
+	if len_allowances > len(allowance_type) {
+		return false, Err{Message: printer.Sprintf("Allowances must be less than or equal to %d", len(allowance_type))}
 	} else if len_allowances > 0 {
-		allowance_type := []string{"donation", "k-receipt"}
 		have_type := []string{}
-		for _, v := range req.Allowances {
+		for _, v := range allowances {
 			allowance_type_low := strings.ToLower(v.AllowanceType)
 			if ok := slices.Contains(allowance_type, allowance_type_low); !ok {
-				return false, Err{Message: "Not found allowanceType"}
+				return false, Err{Message: printer.Sprintf("Not found allowanceType")}
 			}
 			if v.Amount < 0 {
-				return false, Err{Message: "Amount must be greater than 0"}
+				return false, Err{Message: printer.Sprintf("Amount must be greater than 0")}
+			}
+			if v.Amount > Baht(max_amount_baht) {
+				return false, Err{Message: printer.Sprintf("Amount must not be more than %d", max_amount_baht)}
 			}
 			if ok := slices.Contains(have_type, allowance_type_low); ok {
-				return false, Err{Message: "Duplicate allowanceType"}
+				return false, Err{Message: printer.Sprintf("Duplicate allowanceType")}
 			}
 			have_type = append(have_type, allowance_type_low)
 		}
@@ -47,73 +150,313 @@ func (t Tax) validateReq(req ReqTax) (bool, Err) {
 	return true, Err{}
 }
 
-func (t Tax) calculateTax(income float64, rate DB) float64 {
-	cal := (income * rate.Rate) / 100
+func (t Tax) validateReverseReq(req ReqReverseTax, printer *message.Printer) (bool, Err) {
+	if req.TaxYear < 0 {
+		return false, Err{Message: printer.Sprintf("taxYear must be greater than 0")}
+	}
 
-	return cal
+	if (req.NetIncome == nil) == (req.TargetTax == nil) {
+		return false, Err{Message: printer.Sprintf("Either netIncome or targetTax is required")}
+	}
+
+	if req.NetIncome != nil && *req.NetIncome <= 0 {
+		return false, Err{Message: printer.Sprintf("netIncome must be greater than 0")}
+	}
+
+	if req.TargetTax != nil && *req.TargetTax <= 0 {
+		return false, Err{Message: printer.Sprintf("targetTax must be greater than 0")}
+	}
+
+	if req.Wht < 0 {
+		return false, Err{Message: printer.Sprintf("Wht must be greater than 0")}
+	}
+
+	if req.Wht > Baht(max_amount_baht) {
+		return false, Err{Message: printer.Sprintf("Wht must not be more than %d", max_amount_baht)}
+	}
+
+	return true, Err{}
 }
 
-func (t Tax) addTaxLevel(level *[]TaxLevel, rate DB, cal float64) {
-	var format string
-	newP := message.NewPrinter(language.English)
-	if rate.Maximum_salary != 0 {
-		format = newP.Sprintf("%d-%d", int(rate.Minimum_salary), int(rate.Maximum_salary))
-	} else {
-		format = newP.Sprintf("%d ขึ้นไป", int(rate.Minimum_salary))
+// validateSandboxReq checks that brackets start at 0, follow each other
+// without gaps or overlaps the way tax_rates does, e.g. 0-150,000 then
+// 150,001-500,000, and end with an open bracket.
+func (t Tax) validateSandboxReq(req ReqSandbox, printer *message.Printer) (bool, Err) {
+	if ok, err := t.validateReq(req.ReqTax, printer); !ok {
+		return false, err
+	}
+
+	if len(req.Brackets) == 0 {
+		return false, Err{Message: printer.Sprintf("brackets are required")}
+	}
+
+	if req.Brackets[0].MinimumSalary != 0 {
+		return false, Err{Message: printer.Sprintf("brackets must start at 0")}
+	}
+
+	last := len(req.Brackets) - 1
+	for i, v := range req.Brackets {
+		if v.Rate < 0 || v.Rate > 100 {
+			return false, Err{Message: printer.Sprintf("bracket rate must be between 0 and 100")}
+		}
+		if v.MaximumSalary == 0 && i != last {
+			return false, Err{Message: printer.Sprintf("only the last bracket can have no maximumSalary")}
+		}
+		if v.MaximumSalary != 0 && v.MaximumSalary <= v.MinimumSalary {
+			return false, Err{Message: printer.Sprintf("bracket maximumSalary must be greater than minimumSalary")}
+		}
+		if i == 0 {
+			continue
+		}
+
+		next := req.Brackets[i-1].MaximumSalary + Baht(1)
+		if v.MinimumSalary < next {
+			return false, Err{Message: printer.Sprintf("brackets must not overlap")}
+		} else if v.MinimumSalary > next {
+			return false, Err{Message: printer.Sprintf("brackets must not have gaps")}
+		}
+	}
+
+	if req.Brackets[last].MaximumSalary != 0 {
+		return false, Err{Message: printer.Sprintf("last bracket must have no maximumSalary")}
+	}
+
+	have_type := []string{}
+	for _, v := range req.Deductions {
+		deduction_type := strings.ToLower(v.Type)
+		if deduction_type == "" {
+			return false, Err{Message: printer.Sprintf("deduction type is required")}
+		}
+		if v.Amount < 0 {
+			return false, Err{Message: printer.Sprintf("Amount must be greater than 0")}
+		}
+		if ok := slices.Contains(have_type, deduction_type); ok {
+			return false, Err{Message: printer.Sprintf("Duplicate deduction type")}
+		}
+		have_type = append(have_type, deduction_type)
+	}
+
+	return true, Err{}
+}
 
+// lateDates parses the filing and payment date of req. The filing date
+// defaults to the payment date.
+func (t Tax) lateDates(req ReqLateTax, printer *message.Printer) (time.Time, time.Time, Err) {
+	payment, err := time.Parse(date_layout, req.PaymentDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, Err{Message: printer.Sprintf("paymentDate must be in YYYY-MM-DD format")}
+	}
+
+	if req.FilingDate == "" {
+		return payment, payment, Err{}
+	}
+
+	filing, err := time.Parse(date_layout, req.FilingDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, Err{Message: printer.Sprintf("filingDate must be in YYYY-MM-DD format")}
+	}
+
+	if filing.After(payment) {
+		return time.Time{}, time.Time{}, Err{Message: printer.Sprintf("filingDate must not be after paymentDate")}
+	}
+
+	return filing, payment, Err{}
+}
+
+func (t Tax) validateHouseholdReq(req ReqHousehold, printer *message.Printer) (bool, Err) {
+	if req.TaxYear < 0 {
+		return false, Err{Message: printer.Sprintf("taxYear must be greater than 0")}
+	}
+
+	for _, v := range []ReqTax{req.Taxpayer, req.Spouse} {
+		if ok, err := t.validateReq(v, printer); !ok {
+			return false, err
+		}
+		for _, allowance := range v.Allowances {
+			if strings.EqualFold(allowance.AllowanceType, "spouse") {
+				return false, Err{Message: printer.Sprintf("spouse allowance is applied by joint filing")}
+			}
+		}
+	}
+
+	return true, Err{}
+}
+
+func (t Tax) validateWithholdingReq(req ReqWithholding, printer *message.Printer) (bool, Err) {
+	if req.TaxYear < 0 {
+		return false, Err{Message: printer.Sprintf("taxYear must be greater than 0")}
 	}
 
+	if req.Month < 1 || req.Month > 12 {
+		return false, Err{Message: printer.Sprintf("month must be between 1 and 12")}
+	}
+
+	if req.Salary < 0 || req.Bonus < 0 {
+		return false, Err{Message: printer.Sprintf("salary and bonus must be greater than 0")}
+	}
+
+	if req.Salary > Baht(max_amount_baht) || req.Bonus > Baht(max_amount_baht) {
+		return false, Err{Message: printer.Sprintf("salary and bonus must not be more than %d", max_amount_baht)}
+	}
+
+	if req.Salary+req.Bonus == 0 {
+		return false, Err{Message: printer.Sprintf("salary or bonus is required")}
+	}
+
+	if req.YtdIncome < 0 || req.YtdWht < 0 {
+		return false, Err{Message: printer.Sprintf("ytdIncome and ytdWht must be greater than 0")}
+	}
+
+	if req.YtdIncome > Baht(max_amount_baht) {
+		return false, Err{Message: printer.Sprintf("ytdIncome must not be more than %d", max_amount_baht)}
+	}
+
+	if req.Month == 1 && (req.YtdIncome != 0 || req.YtdWht != 0) {
+		return false, Err{Message: printer.Sprintf("ytdIncome and ytdWht must be 0 in month 1")}
+	}
+
+	if req.YtdWht > req.YtdIncome {
+		return false, Err{Message: printer.Sprintf("ytdWht must be less than ytdIncome")}
+	}
+
+	return true, Err{}
+}
+
+func calculateTax(income Money, rate DB) Money {
+	cal := income.Percent(rate.Rate)
+
+	return cal
+}
+
+func addTaxLevel(level *[]TaxLevel, rate DB, cal Money, printer *message.Printer) {
 	*level = append(*level, TaxLevel{
-		Level: format,
+		Level: levelName(rate, printer),
 		Tax:   cal,
 	})
 }
 
-func (t Tax) validateCsv(head []string) (map[string]int, map[string]float64, Err) {
-	simple := []string{"totalIncome", "wht", "donation", "k-receipt"}
+func levelName(rate DB, printer *message.Printer) string {
+	if rate.Maximum_salary != 0 {
+		return printer.Sprintf("%d-%d", rate.Minimum_salary.WholeBaht(), rate.Maximum_salary.WholeBaht())
+	}
+
+	return printer.Sprintf("%d ขึ้นไป", rate.Minimum_salary.WholeBaht())
+}
+
+func (t Tax) validateCsv(head []string, printer *message.Printer) (map[string]int, Err) {
+	simple := []string{"totalIncome", "wht", "pnd94", "taxYear"}
+	deducation_types, err := t.info.GetDeducationTypes()
+	if err != nil {
+		return make(map[string]int), Err{Message: printer.Sprintf("failed to get deduction")}
+	}
+	for _, v := range deducation_types {
+		if allowance_type := strings.ToLower(v); allowance_type != "personal" {
+			simple = append(simple, allowance_type)
+		}
+	}
 	position := make(map[string]int)
-	deducate := make(map[string]float64)
 
 	for i, v := range head {
 		if _, ok := position[v]; !ok && slices.Contains(simple, v) {
 			position[v] = i
 		} else {
-			return make(map[string]int), make(map[string]float64), Err{Message: "invalid csv"}
-		}
-
-		if v == "donation" || v == "k-receipt" {
-			d, err := t.info.GetTaxDeducationByType(cases.Title(language.English, cases.Compact).String(strings.ToLower(v)))
-			if err != nil {
-				return make(map[string]int), make(map[string]float64), Err{Message: "failed to get deduction"}
-			}
-			deducate[v] = d.Amount
+			return make(map[string]int), Err{Message: printer.Sprintf("invalid csv")}
 		}
 	}
 	if _, ok := position["totalIncome"]; !ok {
-		return make(map[string]int), make(map[string]float64), Err{Message: "invalid csv have not totalIncome"}
-	}
-
-	personal, err := t.info.GetTaxDeducationByType("Personal")
-	if err != nil {
-		return make(map[string]int), make(map[string]float64), Err{Message: "failed to get deduction"}
+		return make(map[string]int), Err{Message: printer.Sprintf("invalid csv have not totalIncome")}
 	}
-	deducate["personal"] = personal.Amount
 
-	return position, deducate, Err{}
+	return position, Err{}
 }
 
-func (t Tax) calDeducation(p map[string]int, name_p string, str []string, deducate map[string]float64) (float64, Err) {
+func (t Tax) csvField(p map[string]int, name_p string, str []string, printer *message.Printer) (Money, CsvError) {
 	if _, ok := p[name_p]; ok {
-		dedu, err := strconv.ParseFloat(str[p[name_p]], 64)
+		value, err := ParseMoney(str[p[name_p]])
 		if err != nil {
-			return 0.0, Err{Message: "invalid field " + name_p}
+			return 0, CsvError{Column: name_p, Reason: printer.Sprintf("invalid field %s", name_p)}
 		}
-		if dedu > deducate[name_p] {
-			return deducate[name_p], Err{}
-		} else {
-			return dedu, Err{}
+
+		return value, CsvError{}
+	}
+
+	return 0, CsvError{}
+}
+
+// csvReq builds the request of one csv row. A row without a taxYear, or with
+// an empty one, gets default_year.
+func (t Tax) csvReq(p map[string]int, str []string, default_year int, printer *message.Printer) (ReqTax, CsvError) {
+	var req ReqTax
+	var msg CsvError
+	if i, ok := p["taxYear"]; ok && strings.TrimSpace(str[i]) != "" {
+		tax_year, err := strconv.Atoi(strings.TrimSpace(str[i]))
+		if err != nil || tax_year < 0 {
+			return ReqTax{}, CsvError{Column: "taxYear", Reason: printer.Sprintf("invalid field %s", "taxYear")}
+		}
+		req.TaxYear = tax_year
+	}
+	if req.TaxYear == 0 {
+		req.TaxYear = default_year
+	}
+	if req.TotalIncome, msg = t.csvField(p, "totalIncome", str, printer); msg.Reason != "" {
+		return ReqTax{}, msg
+	}
+	for _, de := range csvAllowanceColumns(p) {
+		amount, msg := t.csvField(p, de, str, printer)
+		if msg.Reason != "" {
+			return ReqTax{}, msg
 		}
+		req.Allowances = append(req.Allowances, Allowance{AllowanceType: de, Amount: amount})
 	}
+	if req.Wht, msg = t.csvField(p, "wht", str, printer); msg.Reason != "" {
+		return ReqTax{}, msg
+	}
+	if req.Pnd94, msg = t.csvField(p, "pnd94", str, printer); msg.Reason != "" {
+		return ReqTax{}, msg
+	}
+
+	return req, CsvError{}
+}
+
+// validateCsvReq runs the checks of the JSON path on a csv row and returns the
+// first column that fails them. validateReq sees the columns added one at a
+// time, so its error can be put on the column that caused it.
+func (t Tax) validateCsvReq(req ReqTax, allowance_type []string, printer *message.Printer) CsvError {
+	partial := ReqTax{TaxYear: req.TaxYear}
+	for _, v := range []struct {
+		column string
+		set    func()
+	}{
+		{"totalIncome", func() { partial.TotalIncome = req.TotalIncome }},
+		{"wht", func() { partial.Wht = req.Wht }},
+		{"pnd94", func() { partial.Pnd94 = req.Pnd94 }},
+	} {
+		v.set()
+		if ok, msg := t.validateReq(partial, printer); !ok {
+			return CsvError{Column: v.column, Reason: msg.Message}
+		}
+	}
+	for _, v := range req.Allowances {
+		if ok, msg := t.validateAllowances([]Allowance{v}, allowance_type, printer); !ok {
+			return CsvError{Column: v.AllowanceType, Reason: msg.Message}
+		}
+	}
+
+	return CsvError{}
+}
+
+// csvAllowanceColumns returns the allowance columns of a validated header in
+// the order they appear in the file.
+func csvAllowanceColumns(p map[string]int) []string {
+	var columns []string
+	for k := range p {
+		if k != "totalIncome" && k != "wht" && k != "pnd94" && k != "taxYear" {
+			columns = append(columns, k)
+		}
+	}
+	slices.SortFunc(columns, func(a, b string) int {
+		return p[a] - p[b]
+	})
 
-	return 0.0, Err{}
+	return columns
 }
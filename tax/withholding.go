@@ -0,0 +1,43 @@
+package tax
+
+// Withhold computes the PND1 withholding for the salary and bonus paid in
+// req.Month. YtdIncome and YtdWht cover the months before req.Month only.
+//
+// The salary is annualized as YtdIncome plus Salary for every remaining month
+// including this one, and the tax on that income less YtdWht is spread evenly
+// over the remaining months, so earlier under or over withholding evens out by
+// December. A bonus is not spread: the extra annual tax it causes is withheld
+// in full in the month it is paid. All income is treated as 40(1).
+func (c Calculator) Withhold(req ReqWithholding) ResWithholding {
+	annualTax := func(income Money) Money {
+		res := c.Calculate(ReqTax{
+			TaxYear:    req.TaxYear,
+			Incomes:    []Income{{IncomeType: "40(1)", Amount: income}},
+			Allowances: req.Allowances,
+		})
+
+		return res.Tax
+	}
+
+	remaining := 13 - req.Month
+	salary_income := req.YtdIncome + req.Salary*Money(remaining)
+	salary_tax := annualTax(salary_income)
+
+	res := ResWithholding{
+		Month:           req.Month,
+		RemainingMonths: remaining,
+		AnnualIncome:    salary_income + req.Bonus,
+		AnnualTax:       salary_tax,
+		YtdWht:          req.YtdWht,
+	}
+	if req.Bonus > 0 {
+		res.AnnualTax = annualTax(res.AnnualIncome)
+		res.BonusWithholding = res.AnnualTax - salary_tax
+	}
+	if due := salary_tax - req.YtdWht; due > 0 {
+		res.SalaryWithholding = (due + Money(remaining)/2) / Money(remaining)
+	}
+	res.Withholding = res.SalaryWithholding + res.BonusWithholding
+
+	return res
+}